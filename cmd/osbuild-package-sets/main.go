@@ -3,11 +3,16 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime/debug"
 	"strings"
+	"sync"
 
 	"github.com/osbuild/images/internal/cmdutil"
 	"github.com/osbuild/images/pkg/blueprint"
@@ -17,12 +22,30 @@ import (
 	"github.com/osbuild/images/pkg/reporegistry"
 )
 
+// packageSetJob is one (distro, arch, image type) tuple to resolve package
+// sets for.
+type packageSetJob struct {
+	distroName  string
+	archName    string
+	imgTypeName string
+	imgType     distro.ImageType
+}
+
+// key returns the "distro/arch/imgtype" identifier used both as the output
+// JSON key and as part of the cache hash input.
+func (j packageSetJob) key() string {
+	return fmt.Sprintf("%s/%s/%s", j.distroName, j.archName, j.imgTypeName)
+}
+
 func main() {
 	// selection args
 	var arches, distros, imgTypes cmdutil.MultiValue
 	flag.Var(&arches, "arches", "comma-separated list of architectures (globs supported)")
 	flag.Var(&distros, "distros", "comma-separated list of distributions (globs supported)")
 	flag.Var(&imgTypes, "types", "comma-separated list of image types (globs supported)")
+	jobs := flag.Int("jobs", 1, "number of distro/arch/image-type tuples to resolve in parallel")
+	cacheDir := flag.String("cache", "", "directory to cache resolved package sets in, keyed by their inputs; skips tuples whose inputs are unchanged")
+	output := flag.String("output", "", "write a single JSON object (keyed by distro/arch/imgtype) to this file instead of streaming one object per tuple to stdout")
 	flag.Parse()
 
 	testedRepoRegistry, err := reporegistry.NewTestedDefault()
@@ -32,11 +55,49 @@ func main() {
 
 	df := distrofactory.NewDefault()
 
-	distros, invalidDistros := distros.ResolveArgValues(testedRepoRegistry.ListDistros())
+	jobList, err := collectJobs(df, testedRepoRegistry, distros, arches, imgTypes)
+	if err != nil {
+		panic(err)
+	}
+
+	results := runJobs(jobList, *jobs, *cacheDir)
+
+	if *cacheDir != "" {
+		if err := writeCacheIndex(*cacheDir, jobList, results); err != nil {
+			panic(err)
+		}
+	}
+
+	if *output != "" {
+		if err := writeOutputFile(*output, results); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	for _, job := range jobList {
+		res := results[job.key()]
+		if res.err != nil {
+			panic(res.err)
+		}
+		_ = encoder.Encode(res.packageSets)
+	}
+}
+
+// collectJobs resolves the -distros/-arches/-types glob selections into a
+// flat list of (distro, arch, image type) tuples, printing the same
+// warnings the previous sequential implementation did for invalid
+// selections.
+func collectJobs(df *distrofactory.Factory, repoRegistry *reporegistry.RepoRegistry, distros, arches, imgTypes cmdutil.MultiValue) ([]packageSetJob, error) {
+	var jobList []packageSetJob
+
+	distroNames, invalidDistros := distros.ResolveArgValues(repoRegistry.ListDistros())
 	if len(invalidDistros) > 0 {
 		fmt.Fprintf(os.Stderr, "WARNING: invalid distro names: [%s]\n", strings.Join(invalidDistros, ","))
 	}
-	for _, distroName := range distros {
+	for _, distroName := range distroNames {
 		distribution := df.GetDistro(distroName)
 		if distribution == nil {
 			fmt.Fprintf(os.Stderr, "WARNING: invalid distro name %q\n", distroName)
@@ -51,7 +112,7 @@ func main() {
 			arch, err := distribution.GetArch(archName)
 			if err != nil {
 				// resolveArgValues should prevent this
-				panic(fmt.Sprintf("invalid arch name %q for distro %q: %s\n", archName, distroName, err.Error()))
+				return nil, fmt.Errorf("invalid arch name %q for distro %q: %w", archName, distroName, err)
 			}
 
 			daImgTypes, invalidImageTypes := imgTypes.ResolveArgValues(arch.ListImageTypes())
@@ -62,34 +123,248 @@ func main() {
 				imgType, err := arch.GetImageType(imgTypeName)
 				if err != nil {
 					// resolveArgValues should prevent this
-					panic(fmt.Sprintf("invalid image type %q for distro %q and arch %q: %s\n", imgTypeName, distroName, archName, err.Error()))
+					return nil, fmt.Errorf("invalid image type %q for distro %q and arch %q: %w", imgTypeName, distroName, archName, err)
 				}
 
-				// set up bare minimum args for image type
-				var customizations *blueprint.Customizations
-				if imgType.Name() == "edge-simplified-installer" || imgType.Name() == "iot-simplified-installer" {
-					customizations = &blueprint.Customizations{
-						InstallationDevice: "/dev/null",
-					}
-				}
-				bp := blueprint.Blueprint{
-					Customizations: customizations,
-				}
-				options := distro.ImageOptions{
-					OSTree: &ostree.ImageOptions{
-						URL: "https://example.com", // required by some image types
-					},
-				}
+				jobList = append(jobList, packageSetJob{
+					distroName:  distroName,
+					archName:    archName,
+					imgTypeName: imgTypeName,
+					imgType:     imgType,
+				})
+			}
+		}
+	}
 
-				manifest, _, err := imgType.Manifest(&bp, options, nil, 0)
-				if err != nil {
-					panic(err)
-				}
+	return jobList, nil
+}
 
-				encoder := json.NewEncoder(os.Stdout)
-				encoder.SetIndent("", "  ")
-				_ = encoder.Encode(manifest.GetPackageSetChains())
-			}
+// packageSetResult is the outcome of resolving a single packageSetJob.
+type packageSetResult struct {
+	packageSets any
+	// cacheFile is the basename (hash+".json") the result was read from or
+	// written to under -cache, or "" if caching is disabled for this run or
+	// writing the cache entry failed.
+	cacheFile string
+	err       error
+}
+
+// runJobs resolves every job's package sets, using up to workerCount
+// goroutines and (if cacheDir is set) a per-tuple on-disk cache to skip
+// jobs whose inputs haven't changed since the last run. The distrofactory
+// and reporegistry referenced by each job's imgType are read-only once
+// constructed, so they can be safely shared across workers.
+func runJobs(jobList []packageSetJob, workerCount int, cacheDir string) map[string]packageSetResult {
+	if workerCount < 1 {
+		workerCount = 1
+	}
+
+	results := make(map[string]packageSetResult, len(jobList))
+	var mu sync.Mutex
+
+	sem := make(chan struct{}, workerCount)
+	var wg sync.WaitGroup
+
+	for _, job := range jobList {
+		job := job
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			res := resolveJob(job, cacheDir)
+
+			mu.Lock()
+			results[job.key()] = res
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// resolveJob computes (or loads from cache) the package sets for a single
+// job.
+func resolveJob(job packageSetJob, cacheDir string) packageSetResult {
+	bp, options := jobArgs(job)
+
+	var cachePath, cacheFile string
+	if cacheDir != "" {
+		hash, err := jobCacheHash(job, bp, options)
+		if err != nil {
+			return packageSetResult{err: err}
+		}
+		cacheFile = hash + ".json"
+		cachePath = filepath.Join(cacheDir, cacheFile)
+
+		if cached, ok := readCache(cachePath); ok {
+			return packageSetResult{packageSets: cached, cacheFile: cacheFile}
+		}
+	}
+
+	manifest, _, err := job.imgType.Manifest(&bp, options, nil, 0)
+	if err != nil {
+		return packageSetResult{err: fmt.Errorf("%s: %w", job.key(), err)}
+	}
+	packageSets := manifest.GetPackageSetChains()
+
+	if cachePath != "" {
+		if err := writeCache(cachePath, packageSets); err != nil {
+			// A cache write failure shouldn't fail the whole run: the
+			// result is still correct, just not persisted for next time.
+			fmt.Fprintf(os.Stderr, "WARNING: failed to write cache for %s: %v\n", job.key(), err)
+			cacheFile = ""
 		}
 	}
+
+	return packageSetResult{packageSets: packageSets, cacheFile: cacheFile}
+}
+
+// jobArgs sets up the bare minimum blueprint and image options needed to
+// generate a manifest for job.imgType.
+func jobArgs(job packageSetJob) (blueprint.Blueprint, distro.ImageOptions) {
+	var customizations *blueprint.Customizations
+	if job.imgTypeName == "edge-simplified-installer" || job.imgTypeName == "iot-simplified-installer" {
+		customizations = &blueprint.Customizations{
+			InstallationDevice: "/dev/null",
+		}
+	}
+	bp := blueprint.Blueprint{
+		Customizations: customizations,
+	}
+	options := distro.ImageOptions{
+		OSTree: &ostree.ImageOptions{
+			URL: "https://example.com", // required by some image types
+		},
+	}
+	return bp, options
+}
+
+// jobCacheHash hashes the tuple (distro, arch, imgtype, blueprint, options,
+// module version) so that a cache entry is only reused when none of those
+// inputs have changed.
+func jobCacheHash(job packageSetJob, bp blueprint.Blueprint, options distro.ImageOptions) (string, error) {
+	bpJSON, err := json.Marshal(bp)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal blueprint for %s: %w", job.key(), err)
+	}
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal image options for %s: %w", job.key(), err)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00", job.distroName, job.archName, job.imgTypeName)
+	h.Write(bpJSON)
+	h.Write([]byte{0})
+	h.Write(optionsJSON)
+	h.Write([]byte{0})
+	fmt.Fprintf(h, "%s", moduleVersion())
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// moduleVersion returns a string that identifies the source the running
+// binary was built from, for use as part of jobCacheHash's invalidation
+// key. info.Main.Version reports "(devel)" for any ordinary local "go
+// build"/"go run" of the main module and doesn't vary with commit or
+// working-tree changes, so it can't detect a code change between runs; the
+// VCS settings Go embeds alongside it (vcs.revision, vcs.modified) do, so
+// use those instead. Falls back to "unknown" when build info or VCS
+// settings aren't available (e.g. built without module support, or from a
+// source tree outside of version control).
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	var revision string
+	var modified bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision == "" {
+		return "unknown"
+	}
+	if modified {
+		return revision + "+dirty"
+	}
+	return revision
+}
+
+func readCache(path string) (any, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// writeCacheIndex writes cacheDir/index.json, mapping each job's
+// "distro/arch/imgtype" key to the cache file its result lives in. This
+// makes a cache directory inspectable (which entries belong to which
+// tuple, which ones missed) without recomputing every job's hash.
+func writeCacheIndex(cacheDir string, jobList []packageSetJob, results map[string]packageSetResult) error {
+	index := make(map[string]string, len(jobList))
+	for _, job := range jobList {
+		res := results[job.key()]
+		if res.err != nil || res.cacheFile == "" {
+			continue
+		}
+		index[job.key()] = res.cacheFile
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache index: %w", err)
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(cacheDir, "index.json"), data, 0644)
+}
+
+func writeCache(path string, packageSets any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(packageSets, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// writeOutputFile streams results as a single well-formed JSON object
+// (keyed by "distro/arch/imgtype") instead of the concatenated
+// one-object-per-tuple encoder output used for stdout, so downstream
+// tooling can load the whole run with a single json.Unmarshal.
+func writeOutputFile(path string, results map[string]packageSetResult) error {
+	out := make(map[string]any, len(results))
+	for key, res := range results {
+		if res.err != nil {
+			return res.err
+		}
+		out[key] = res.packageSets
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0644)
 }
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/osbuild/images/pkg/blueprint"
+	"github.com/osbuild/images/pkg/distro"
+	"github.com/osbuild/images/pkg/ostree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testJobArgs() (packageSetJob, blueprint.Blueprint, distro.ImageOptions) {
+	job := packageSetJob{distroName: "fedora-40", archName: "x86_64", imgTypeName: "qcow2"}
+	bp := blueprint.Blueprint{}
+	options := distro.ImageOptions{
+		OSTree: &ostree.ImageOptions{URL: "https://example.com"},
+	}
+	return job, bp, options
+}
+
+func TestJobCacheHashDeterministic(t *testing.T) {
+	job, bp, options := testJobArgs()
+
+	hash1, err := jobCacheHash(job, bp, options)
+	require.NoError(t, err)
+	hash2, err := jobCacheHash(job, bp, options)
+	require.NoError(t, err)
+
+	assert.Equal(t, hash1, hash2, "hashing the same inputs twice must produce the same hash")
+}
+
+func TestJobCacheHashSensitiveToInputs(t *testing.T) {
+	job, bp, options := testJobArgs()
+	baseHash, err := jobCacheHash(job, bp, options)
+	require.NoError(t, err)
+
+	otherJob := job
+	otherJob.imgTypeName = "ami"
+	jobHash, err := jobCacheHash(otherJob, bp, options)
+	require.NoError(t, err)
+	assert.NotEqual(t, baseHash, jobHash, "changing the job tuple must change the hash")
+
+	otherBP := bp
+	otherBP.Customizations = &blueprint.Customizations{Hostname: "other"}
+	bpHash, err := jobCacheHash(job, otherBP, options)
+	require.NoError(t, err)
+	assert.NotEqual(t, baseHash, bpHash, "changing the blueprint must change the hash")
+
+	otherOptions := options
+	otherOptions.OSTree = &ostree.ImageOptions{URL: "https://example.org"}
+	optionsHash, err := jobCacheHash(job, bp, otherOptions)
+	require.NoError(t, err)
+	assert.NotEqual(t, baseHash, optionsHash, "changing the image options must change the hash")
+}
+
+func TestReadCacheMissingOrCorrupt(t *testing.T) {
+	dir := t.TempDir()
+
+	_, ok := readCache(filepath.Join(dir, "missing.json"))
+	assert.False(t, ok, "reading a nonexistent cache file must report a miss, not an error")
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	require.NoError(t, os.WriteFile(corrupt, []byte("not json"), 0644))
+	_, ok = readCache(corrupt)
+	assert.False(t, ok, "reading a corrupt cache file must report a miss, not an error")
+}
+
+func TestWriteCacheReadCacheRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "entry.json")
+
+	packageSets := map[string]any{"build": []any{"bash-5.2-1.fc38"}}
+	require.NoError(t, writeCache(path, packageSets))
+
+	got, ok := readCache(path)
+	require.True(t, ok)
+	assert.Equal(t, packageSets["build"], got.(map[string]any)["build"])
+}
+
+func TestWriteCacheIndex(t *testing.T) {
+	dir := t.TempDir()
+
+	jobList := []packageSetJob{
+		{distroName: "fedora-40", archName: "x86_64", imgTypeName: "qcow2"},
+		{distroName: "fedora-40", archName: "x86_64", imgTypeName: "ami"},
+		{distroName: "fedora-40", archName: "aarch64", imgTypeName: "qcow2"},
+	}
+	results := map[string]packageSetResult{
+		jobList[0].key(): {cacheFile: "abc123.json"},
+		jobList[1].key(): {err: assert.AnError},
+		jobList[2].key(): {cacheFile: ""},
+	}
+
+	require.NoError(t, writeCacheIndex(dir, jobList, results))
+
+	index, ok := readCache(filepath.Join(dir, "index.json"))
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{jobList[0].key(): "abc123.json"}, index,
+		"only successful entries with a cache file should be indexed")
+}
+
+func TestWriteOutputFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "output.json")
+
+	results := map[string]packageSetResult{
+		"fedora-40/x86_64/qcow2": {packageSets: map[string]any{"build": []any{"bash"}}},
+	}
+	require.NoError(t, writeOutputFile(path, results))
+
+	out, ok := readCache(path)
+	require.True(t, ok)
+	assert.Contains(t, out.(map[string]any), "fedora-40/x86_64/qcow2")
+}
+
+func TestWriteOutputFilePropagatesJobError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "output.json")
+
+	results := map[string]packageSetResult{
+		"fedora-40/x86_64/qcow2": {err: assert.AnError},
+	}
+	err := writeOutputFile(path, results)
+	assert.ErrorIs(t, err, assert.AnError)
+}
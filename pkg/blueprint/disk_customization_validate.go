@@ -0,0 +1,111 @@
+package blueprint
+
+import (
+	"fmt"
+
+	"github.com/osbuild/images/pkg/datasizes"
+)
+
+// validFSTypes are the filesystem types accepted for a "plain" partition or
+// an LVM logical volume (btrfs subvolumes have no fs_type and are not checked
+// against this list). This does not include "none",
+// which is reserved for the BIOS boot partition and is never user-selectable.
+var validFSTypes = map[string]bool{
+	"xfs":  true,
+	"ext4": true,
+	"ext3": true,
+	"vfat": true,
+}
+
+// requiredMinSizes lists the minimum size (in bytes) that specific
+// mountpoints must satisfy, regardless of the size requested by the user.
+// These mirror the constraints documented at
+// https://osbuild.org/docs/user-guide/partitioning.
+var requiredMinSizes = map[string]uint64{
+	"/":     1 * datasizes.GiB,
+	"/boot": 500 * datasizes.MiB,
+}
+
+// RequiredMinSize returns the minimum size (in bytes) that mountpoint must
+// have, and whether one is defined at all. It is exported so pkg/disk can
+// apply the same default when a partition or logical volume at a required
+// mountpoint leaves MinSize unset (0, the documented "defaults depend on
+// payload and mountpoints" zero value).
+func RequiredMinSize(mountpoint string) (uint64, bool) {
+	size, ok := requiredMinSizes[mountpoint]
+	return size, ok
+}
+
+// Validate checks the DiskCustomization for internal consistency: that
+// mountpoints are unique across all partition payloads, that each
+// filesystem's fs_type (if set) is one of the supported types, and that
+// mountpoints with a required minimum size meet it.
+func (p *DiskCustomization) Validate() error {
+	if p == nil {
+		return nil
+	}
+
+	mountpoints := make(map[string]bool)
+
+	checkMountpoint := func(mountpoint string, minSize uint64) error {
+		if mountpoint == "" {
+			return nil
+		}
+		if mountpoints[mountpoint] {
+			return fmt.Errorf("mountpoint %q is defined more than once", mountpoint)
+		}
+		mountpoints[mountpoint] = true
+
+		if required, ok := requiredMinSizes[mountpoint]; ok && minSize != 0 && minSize < required {
+			return fmt.Errorf("mountpoint %q must be at least %d bytes, got %d", mountpoint, required, minSize)
+		}
+		return nil
+	}
+
+	checkFSType := func(fsType string) error {
+		if fsType == "" {
+			return nil
+		}
+		if !validFSTypes[fsType] {
+			return fmt.Errorf("unsupported filesystem type %q", fsType)
+		}
+		return nil
+	}
+
+	for _, part := range p.Partitions {
+		switch part.Type {
+		case "", "plain":
+			if err := checkFSType(part.FSType); err != nil {
+				return err
+			}
+			if err := checkMountpoint(part.Mountpoint, part.MinSize); err != nil {
+				return err
+			}
+			if err := part.Encryption.Validate(); err != nil {
+				return fmt.Errorf("partition %q: %w", part.Mountpoint, err)
+			}
+		case "lvm":
+			for _, lv := range part.LogicalVolumes {
+				if err := checkFSType(lv.FSType); err != nil {
+					return err
+				}
+				if err := checkMountpoint(lv.Mountpoint, lv.MinSize); err != nil {
+					return err
+				}
+				if err := lv.Encryption.Validate(); err != nil {
+					return fmt.Errorf("logical volume %q: %w", lv.Mountpoint, err)
+				}
+			}
+		case "btrfs":
+			for _, subvol := range part.Subvolumes {
+				if err := checkMountpoint(subvol.Mountpoint, 0); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("unknown partition type: %q", part.Type)
+		}
+	}
+
+	return nil
+}
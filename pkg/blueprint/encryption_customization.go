@@ -0,0 +1,93 @@
+package blueprint
+
+import "fmt"
+
+// EncryptionCustomization describes how a filesystem on a plain partition or
+// LVM logical volume should be encrypted. It is the Encryption field of
+// [FilesystemTypedCustomization], so it is available on both "plain"
+// partitions and "lvm" logical volumes.
+type EncryptionCustomization struct {
+	// The LUKS format to use. Currently only "luks2" is supported.
+	Type string `json:"type" toml:"type"`
+
+	// The passphrase used to unlock the LUKS container (required).
+	Passphrase string `json:"passphrase" toml:"passphrase"`
+
+	// The cipher used by the LUKS container (optional, defaults to the
+	// cryptsetup default, e.g. "aes-xts-plain64").
+	Cipher string `json:"cipher,omitempty" toml:"cipher,omitempty"`
+
+	// Parameters for the key derivation function used to protect the
+	// passphrase (optional, defaults to cryptsetup's defaults for Type).
+	PBKDF PBKDFCustomization `json:"pbkdf,omitempty" toml:"pbkdf,omitempty"`
+
+	// The sector size used by the LUKS container (optional, defaults to 512).
+	SectorSize uint64 `json:"sector_size,omitempty" toml:"sector_size,omitempty"`
+
+	// Label for the LUKS container (optional).
+	Label string `json:"label,omitempty" toml:"label,omitempty"`
+}
+
+// PBKDFCustomization configures the password-based key derivation function
+// used by a LUKS container.
+type PBKDFCustomization struct {
+	// The PBKDF algorithm: "argon2id" (default) or "pbkdf2".
+	Type string `json:"type,omitempty" toml:"type,omitempty"`
+
+	// Memory cost in KiB (argon2id only).
+	Memory uint64 `json:"memory,omitempty" toml:"memory,omitempty"`
+
+	// Number of iterations.
+	Iterations uint64 `json:"iterations,omitempty" toml:"iterations,omitempty"`
+
+	// Degree of parallelism (argon2id only).
+	Parallelism uint64 `json:"parallelism,omitempty" toml:"parallelism,omitempty"`
+}
+
+// kernel-accepted ranges for the PBKDF parameters, mirroring the limits
+// enforced by cryptsetup's luksFormat.
+const (
+	argon2MinMemoryKiB   = 32 * 1024
+	argon2MaxMemoryKiB   = 4 * 1024 * 1024
+	argon2MaxParallelism = 4
+	pbkdf2MinIterations  = 1000
+	argon2MinIterations  = 4
+)
+
+// Validate checks that the encryption customization is usable: the LUKS
+// format is supported, a non-empty passphrase was given, and any PBKDF
+// parameters fall within the ranges the kernel and cryptsetup accept.
+func (e *EncryptionCustomization) Validate() error {
+	if e == nil {
+		return nil
+	}
+
+	if e.Type != "luks2" {
+		return fmt.Errorf("unsupported encryption type: %q", e.Type)
+	}
+
+	if e.Passphrase == "" {
+		return fmt.Errorf("encryption passphrase must not be empty")
+	}
+
+	switch e.PBKDF.Type {
+	case "", "argon2id":
+		if e.PBKDF.Memory != 0 && (e.PBKDF.Memory < argon2MinMemoryKiB || e.PBKDF.Memory > argon2MaxMemoryKiB) {
+			return fmt.Errorf("argon2id pbkdf memory must be between %d and %d KiB, got %d", argon2MinMemoryKiB, argon2MaxMemoryKiB, e.PBKDF.Memory)
+		}
+		if e.PBKDF.Parallelism != 0 && e.PBKDF.Parallelism > argon2MaxParallelism {
+			return fmt.Errorf("argon2id pbkdf parallelism must be at most %d, got %d", argon2MaxParallelism, e.PBKDF.Parallelism)
+		}
+		if e.PBKDF.Iterations != 0 && e.PBKDF.Iterations < argon2MinIterations {
+			return fmt.Errorf("argon2id pbkdf iterations must be at least %d, got %d", argon2MinIterations, e.PBKDF.Iterations)
+		}
+	case "pbkdf2":
+		if e.PBKDF.Iterations != 0 && e.PBKDF.Iterations < pbkdf2MinIterations {
+			return fmt.Errorf("pbkdf2 iterations must be at least %d, got %d", pbkdf2MinIterations, e.PBKDF.Iterations)
+		}
+	default:
+		return fmt.Errorf("unsupported pbkdf type: %q", e.PBKDF.Type)
+	}
+
+	return nil
+}
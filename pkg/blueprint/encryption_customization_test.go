@@ -0,0 +1,145 @@
+package blueprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptionCustomizationValidateNil(t *testing.T) {
+	var e *EncryptionCustomization
+	assert.NoError(t, e.Validate())
+}
+
+func TestEncryptionCustomizationValidate(t *testing.T) {
+	type testCase struct {
+		name    string
+		enc     EncryptionCustomization
+		wantErr bool
+	}
+
+	testCases := []testCase{
+		{
+			name:    "unsupported type",
+			enc:     EncryptionCustomization{Type: "luks1", Passphrase: "x"},
+			wantErr: true,
+		},
+		{
+			name:    "empty passphrase",
+			enc:     EncryptionCustomization{Type: "luks2"},
+			wantErr: true,
+		},
+		{
+			name:    "defaults are valid",
+			enc:     EncryptionCustomization{Type: "luks2", Passphrase: "x"},
+			wantErr: false,
+		},
+		{
+			name: "argon2id memory at minimum",
+			enc: EncryptionCustomization{
+				Type: "luks2", Passphrase: "x",
+				PBKDF: PBKDFCustomization{Type: "argon2id", Memory: argon2MinMemoryKiB},
+			},
+			wantErr: false,
+		},
+		{
+			name: "argon2id memory below minimum",
+			enc: EncryptionCustomization{
+				Type: "luks2", Passphrase: "x",
+				PBKDF: PBKDFCustomization{Type: "argon2id", Memory: argon2MinMemoryKiB - 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "argon2id memory at maximum",
+			enc: EncryptionCustomization{
+				Type: "luks2", Passphrase: "x",
+				PBKDF: PBKDFCustomization{Type: "argon2id", Memory: argon2MaxMemoryKiB},
+			},
+			wantErr: false,
+		},
+		{
+			name: "argon2id memory above maximum",
+			enc: EncryptionCustomization{
+				Type: "luks2", Passphrase: "x",
+				PBKDF: PBKDFCustomization{Type: "argon2id", Memory: argon2MaxMemoryKiB + 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "argon2id parallelism at maximum",
+			enc: EncryptionCustomization{
+				Type: "luks2", Passphrase: "x",
+				PBKDF: PBKDFCustomization{Type: "argon2id", Parallelism: argon2MaxParallelism},
+			},
+			wantErr: false,
+		},
+		{
+			name: "argon2id parallelism above maximum",
+			enc: EncryptionCustomization{
+				Type: "luks2", Passphrase: "x",
+				PBKDF: PBKDFCustomization{Type: "argon2id", Parallelism: argon2MaxParallelism + 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "argon2id iterations at minimum",
+			enc: EncryptionCustomization{
+				Type: "luks2", Passphrase: "x",
+				PBKDF: PBKDFCustomization{Type: "argon2id", Iterations: argon2MinIterations},
+			},
+			wantErr: false,
+		},
+		{
+			name: "argon2id iterations below minimum",
+			enc: EncryptionCustomization{
+				Type: "luks2", Passphrase: "x",
+				PBKDF: PBKDFCustomization{Type: "argon2id", Iterations: argon2MinIterations - 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty pbkdf type defaults to argon2id rules",
+			enc: EncryptionCustomization{
+				Type: "luks2", Passphrase: "x",
+				PBKDF: PBKDFCustomization{Iterations: argon2MinIterations - 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "pbkdf2 iterations at minimum",
+			enc: EncryptionCustomization{
+				Type: "luks2", Passphrase: "x",
+				PBKDF: PBKDFCustomization{Type: "pbkdf2", Iterations: pbkdf2MinIterations},
+			},
+			wantErr: false,
+		},
+		{
+			name: "pbkdf2 iterations below minimum",
+			enc: EncryptionCustomization{
+				Type: "luks2", Passphrase: "x",
+				PBKDF: PBKDFCustomization{Type: "pbkdf2", Iterations: pbkdf2MinIterations - 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported pbkdf type",
+			enc: EncryptionCustomization{
+				Type: "luks2", Passphrase: "x",
+				PBKDF: PBKDFCustomization{Type: "scrypt"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.enc.Validate()
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -51,6 +51,9 @@ type FilesystemTypedCustomization struct {
 	Mountpoint string `json:"mountpoint" toml:"mountpoint"`
 	Label      string `json:"label,omitempty" toml:"label,omitempty"`
 	FSType     string `json:"fs_type,omitempty" toml:"fs_type,omitempty"`
+
+	// Encrypt the filesystem with LUKS (optional, defaults to no encryption).
+	Encryption *EncryptionCustomization `json:"encryption,omitempty" toml:"encryption,omitempty"`
 }
 
 // An LVM volume group with one or more logical volumes.
@@ -0,0 +1,63 @@
+package blueprint
+
+import "encoding/json"
+
+// VolumeCustomization is a sibling of [DiskCustomization] for the
+// declarative volume-provisioning model: instead of describing a fixed
+// partition layout, the user describes a set of named volumes and lets the
+// provisioner match each one against a physical disk using a
+// [ProvisioningCustomization.DiskSelector] expression.
+type VolumeCustomization struct {
+	// Name of the volume (required, used for logging and error messages).
+	Name string `json:"name" toml:"name"`
+
+	// How to pick the physical disk this volume should live on.
+	Provisioning ProvisioningCustomization `json:"provisioning" toml:"provisioning"`
+
+	// The filesystem to place on the volume.
+	Filesystem FilesystemTypedCustomization `json:"filesystem" toml:"filesystem"`
+}
+
+// ProvisioningCustomization describes how a [VolumeCustomization] should be
+// matched to a physical disk.
+type ProvisioningCustomization struct {
+	// A boolean expression over the candidate disk's properties (see
+	// disk.size, disk.model, disk.transport, disk.rotational, disk.system,
+	// disk.wwn), e.g. "disk.size > 100GB && disk.transport == 'nvme'"
+	// (required).
+	DiskSelector string `json:"disk_selector" toml:"disk_selector"`
+
+	// Minimum size of the volume, in bytes (optional, defaults to 0).
+	MinSize uint64 `json:"min_size,omitempty" toml:"min_size,omitempty"`
+
+	// Whether the volume should grow to fill the remaining space on its
+	// disk after all fixed-size volumes have been placed (optional,
+	// defaults to false).
+	Grow bool `json:"grow,omitempty" toml:"grow,omitempty"`
+}
+
+// Custom JSON unmarshaller for ProvisioningCustomization for handling the
+// conversion of data sizes (min_size) expressed as strings (e.g. "50GiB")
+// to uint64, the same way LVCustomization and PartitionCustomization do.
+func (p *ProvisioningCustomization) UnmarshalJSON(data []byte) error {
+	var provAnySize struct {
+		DiskSelector string `json:"disk_selector" toml:"disk_selector"`
+		MinSize      any    `json:"min_size,omitempty" toml:"min_size,omitempty"`
+		Grow         bool   `json:"grow,omitempty" toml:"grow,omitempty"`
+	}
+	if err := json.Unmarshal(data, &provAnySize); err != nil {
+		return err
+	}
+
+	p.DiskSelector = provAnySize.DiskSelector
+	p.Grow = provAnySize.Grow
+
+	if provAnySize.MinSize != nil {
+		size, err := decodeSize(provAnySize.MinSize)
+		if err != nil {
+			return err
+		}
+		p.MinSize = size
+	}
+	return nil
+}
@@ -0,0 +1,489 @@
+package disk
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/osbuild/images/pkg/blueprint"
+	"github.com/osbuild/images/pkg/platform"
+)
+
+// GPT partition type GUIDs for the partitions this constructor may need to
+// auto-inject. These match the well-known types used by the rest of the
+// disk package's hand-written partition tables.
+const (
+	customPartitionTypeBIOSBoot = "21686148-6449-6E6F-744E-656564454649"
+	customPartitionTypeESP      = "C12A7328-F81F-11D2-BA4B-00A0C93EC93B"
+)
+
+// NewCustomPartitionTable builds a complete PartitionTable from a
+// blueprint.DiskCustomization. The base partition table provides the
+// starting layout (sector size, default partition type, and any
+// distro-specific partitions that must always be present); the
+// customization then overlays user-requested partitions, logical volumes,
+// and btrfs subvolumes on top of it.
+//
+// In addition to materializing what the user explicitly asked for, this
+// also:
+//   - grows an LVM partition when the sizes of its logical volumes add up
+//     to more than its own configured MinSize (btrfs partitions keep their
+//     configured MinSize, since subvolumes carry no size);
+//   - injects a BIOS boot partition and/or ESP when the target bootMode
+//     requires one and the customization didn't define it;
+//   - injects /boot and / when the customization doesn't define them,
+//     using defaultType to decide whether / is a plain partition, an LVM
+//     logical volume, or a btrfs subvolume;
+//   - generates UUIDs and volume group names from rng when the
+//     customization leaves them unset.
+func NewCustomPartitionTable(base *PartitionTable, custom *blueprint.DiskCustomization, defaultType string, bootMode platform.BootMode, rng *rand.Rand) (*PartitionTable, error) {
+	if custom == nil {
+		custom = &blueprint.DiskCustomization{}
+	}
+
+	if err := custom.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid disk customization: %w", err)
+	}
+
+	pt := base.Clone()
+
+	mountpoints := collectMountpoints(custom)
+
+	for _, partCustom := range custom.Partitions {
+		part, err := newPartitionFromCustomization(&partCustom, rng)
+		if err != nil {
+			return nil, err
+		}
+		pt.Partitions = append(pt.Partitions, *part)
+	}
+
+	if err := ensureBootPartitions(pt, mountpoints, bootMode, rng); err != nil {
+		return nil, err
+	}
+
+	if !mountpoints["/boot"] {
+		pt.Partitions = append(pt.Partitions, newPlainPartition("/boot", "xfs", 500*MiB, rng))
+	}
+
+	if !mountpoints["/"] {
+		rootPart, err := newRootPartition(defaultType, rng)
+		if err != nil {
+			return nil, err
+		}
+		pt.Partitions = append(pt.Partitions, *rootPart)
+	}
+
+	growContainers(pt)
+
+	if err := validateNoMountpointCollisions(pt); err != nil {
+		return nil, err
+	}
+
+	return pt, nil
+}
+
+// validateNoMountpointCollisions returns an error if two partitions (or
+// their nested LVM/btrfs payloads) claim the same mountpoint. This is a
+// last line of defense against bugs in the auto-injection logic above,
+// since blueprint.DiskCustomization.Validate() only checks the
+// user-supplied customization, not the partitions this constructor adds on
+// top of it.
+func validateNoMountpointCollisions(pt *PartitionTable) error {
+	seen := make(map[string]bool)
+	for _, part := range pt.Partitions {
+		for _, mountpoint := range payloadMountpoints(part.Payload) {
+			if seen[mountpoint] {
+				return fmt.Errorf("mountpoint %q is used by more than one partition", mountpoint)
+			}
+			seen[mountpoint] = true
+		}
+	}
+	return nil
+}
+
+// payloadMountpoints returns every mountpoint claimed by payload, recursing
+// through LUKS containers, LVM volume groups, and btrfs subvolumes.
+func payloadMountpoints(payload PayloadEntity) []string {
+	switch p := payload.(type) {
+	case *Filesystem:
+		if p.Mountpoint == "" {
+			return nil
+		}
+		return []string{p.Mountpoint}
+	case *LUKSContainer:
+		return payloadMountpoints(p.Payload)
+	case *LVMVolumeGroup:
+		var mountpoints []string
+		for _, lv := range p.LogicalVolumes {
+			mountpoints = append(mountpoints, payloadMountpoints(lv.Payload)...)
+		}
+		return mountpoints
+	case *Btrfs:
+		var mountpoints []string
+		for _, subvol := range p.Subvolumes {
+			if subvol.Mountpoint != "" {
+				mountpoints = append(mountpoints, subvol.Mountpoint)
+			}
+		}
+		return mountpoints
+	default:
+		return nil
+	}
+}
+
+// collectMountpoints returns the set of mountpoints already defined anywhere
+// in the customization (plain partitions, LVM logical volumes, and btrfs
+// subvolumes).
+func collectMountpoints(custom *blueprint.DiskCustomization) map[string]bool {
+	mountpoints := make(map[string]bool)
+	for _, part := range custom.Partitions {
+		switch part.Type {
+		case "", "plain":
+			if part.Mountpoint != "" {
+				mountpoints[part.Mountpoint] = true
+			}
+		case "lvm":
+			for _, lv := range part.LogicalVolumes {
+				if lv.Mountpoint != "" {
+					mountpoints[lv.Mountpoint] = true
+				}
+			}
+		case "btrfs":
+			for _, subvol := range part.Subvolumes {
+				if subvol.Mountpoint != "" {
+					mountpoints[subvol.Mountpoint] = true
+				}
+			}
+		}
+	}
+	return mountpoints
+}
+
+// newPartitionFromCustomization materializes a single Partition for a
+// PartitionCustomization, dispatching on its payload type.
+func newPartitionFromCustomization(part *blueprint.PartitionCustomization, rng *rand.Rand) (*Partition, error) {
+	switch part.Type {
+	case "", "plain":
+		return newPlainPartitionFromCustomization(part, rng)
+	case "lvm":
+		return newLVMPartitionFromCustomization(part, rng)
+	case "btrfs":
+		return newBtrfsPartitionFromCustomization(part, rng)
+	default:
+		return nil, fmt.Errorf("unknown partition type: %q", part.Type)
+	}
+}
+
+// effectiveMinSize returns minSize unchanged if it's nonzero. Otherwise, if
+// mountpoint is one of the required mountpoints (see
+// blueprint.RequiredMinSize), it returns that mountpoint's required minimum
+// instead of letting the unset size through as 0: a partition or logical
+// volume with no explicit size at a required mountpoint would otherwise end
+// up with no size at all, since collectMountpoints already considers the
+// mountpoint present and skips the auto-injection defaulting paths
+// (newRootPartition, the /boot injection) that would normally supply one.
+func effectiveMinSize(minSize uint64, mountpoint string) uint64 {
+	if minSize != 0 {
+		return minSize
+	}
+	if required, ok := blueprint.RequiredMinSize(mountpoint); ok {
+		return required
+	}
+	return minSize
+}
+
+func newPlainPartitionFromCustomization(part *blueprint.PartitionCustomization, rng *rand.Rand) (*Partition, error) {
+	var payload PayloadEntity = &Filesystem{
+		Type:       part.FSType,
+		Label:      part.Label,
+		Mountpoint: part.Mountpoint,
+		UUID:       genUUID(rng),
+	}
+
+	payload, err := wrapWithLUKS(payload, part.Encryption, rng)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Partition{
+		Size:    effectiveMinSize(part.MinSize, part.Mountpoint),
+		UUID:    genUUID(rng),
+		Payload: payload,
+	}, nil
+}
+
+func newLVMPartitionFromCustomization(part *blueprint.PartitionCustomization, rng *rand.Rand) (*Partition, error) {
+	vgName := part.Name
+	if vgName == "" {
+		vgName = genVGName(rng)
+	}
+
+	vg := &LVMVolumeGroup{
+		Name: vgName,
+	}
+
+	var lvSizeSum uint64
+	for _, lvCustom := range part.LogicalVolumes {
+		lvName := lvCustom.Name
+		if lvName == "" {
+			lvName = fmt.Sprintf("lv-%s", genShortID(rng))
+		}
+
+		var lvPayload PayloadEntity = &Filesystem{
+			Type:       lvCustom.FSType,
+			Label:      lvCustom.Label,
+			Mountpoint: lvCustom.Mountpoint,
+			UUID:       genUUID(rng),
+		}
+
+		lvPayload, err := wrapWithLUKS(lvPayload, lvCustom.Encryption, rng)
+		if err != nil {
+			return nil, err
+		}
+
+		lvSize := effectiveMinSize(lvCustom.MinSize, lvCustom.Mountpoint)
+		vg.LogicalVolumes = append(vg.LogicalVolumes, LVMLogicalVolume{
+			Name:    lvName,
+			Size:    lvSize,
+			Payload: lvPayload,
+		})
+		lvSizeSum += lvSize
+	}
+
+	size := part.MinSize
+	if lvSizeSum > size {
+		size = lvSizeSum
+	}
+
+	return &Partition{
+		Size:    size,
+		UUID:    genUUID(rng),
+		Payload: vg,
+	}, nil
+}
+
+func newBtrfsPartitionFromCustomization(part *blueprint.PartitionCustomization, rng *rand.Rand) (*Partition, error) {
+	btrfs := &Btrfs{
+		UUID: genUUID(rng),
+	}
+
+	for _, subvolCustom := range part.Subvolumes {
+		btrfs.Subvolumes = append(btrfs.Subvolumes, BtrfsSubvolume{
+			Name:       subvolCustom.Name,
+			Mountpoint: subvolCustom.Mountpoint,
+		})
+	}
+
+	return &Partition{
+		Size:    part.MinSize,
+		UUID:    genUUID(rng),
+		Payload: btrfs,
+	}, nil
+}
+
+func newPlainPartition(mountpoint, fsType string, minSize uint64, rng *rand.Rand) Partition {
+	return Partition{
+		Size: minSize,
+		UUID: genUUID(rng),
+		Payload: &Filesystem{
+			Type:       fsType,
+			Mountpoint: mountpoint,
+			UUID:       genUUID(rng),
+		},
+	}
+}
+
+// newRootPartition creates the "/" mountpoint as a plain partition, an LVM
+// logical volume, or a btrfs subvolume, depending on defaultType.
+func newRootPartition(defaultType string, rng *rand.Rand) (*Partition, error) {
+	const rootMinSize = 2 * GiB
+
+	switch defaultType {
+	case "", "plain":
+		part := newPlainPartition("/", "xfs", rootMinSize, rng)
+		return &part, nil
+	case "lvm":
+		return &Partition{
+			Size: rootMinSize,
+			UUID: genUUID(rng),
+			Payload: &LVMVolumeGroup{
+				Name: genVGName(rng),
+				LogicalVolumes: []LVMLogicalVolume{
+					{
+						Name: "rootlv",
+						Size: rootMinSize,
+						Payload: &Filesystem{
+							Type:       "xfs",
+							Mountpoint: "/",
+							UUID:       genUUID(rng),
+						},
+					},
+				},
+			},
+		}, nil
+	case "btrfs":
+		return &Partition{
+			Size: rootMinSize,
+			UUID: genUUID(rng),
+			Payload: &Btrfs{
+				UUID: genUUID(rng),
+				Subvolumes: []BtrfsSubvolume{
+					{Name: "root", Mountpoint: "/"},
+				},
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown default partition type: %q", defaultType)
+	}
+}
+
+// ensureBootPartitions injects a BIOS boot partition and/or an ESP into pt
+// when bootMode requires them and they aren't already present.
+func ensureBootPartitions(pt *PartitionTable, mountpoints map[string]bool, bootMode platform.BootMode, rng *rand.Rand) error {
+	// The BIOS boot partition carries no filesystem or mountpoint a user
+	// could set via blueprint.PartitionCustomization, so the GUID it was
+	// injected with earlier (by this function or the base table) is the
+	// only way to detect it.
+	hasBIOSBoot := false
+	for _, part := range pt.Partitions {
+		if part.Type == customPartitionTypeBIOSBoot {
+			hasBIOSBoot = true
+		}
+	}
+
+	// An ESP, unlike the BIOS boot partition, is just a "plain" partition
+	// with fs_type=vfat and mountpoint=/boot/efi from the customization's
+	// point of view: it never gets the ESP GUID, since there is no field
+	// on PartitionCustomization to set it. Detect it by mountpoint instead.
+	hasESP := mountpoints["/boot/efi"]
+
+	switch bootMode {
+	case platform.BOOT_LEGACY:
+		if !hasBIOSBoot {
+			pt.Partitions = append(pt.Partitions, newBIOSBootPartition(rng))
+		}
+	case platform.BOOT_UEFI:
+		if !hasESP {
+			pt.Partitions = append(pt.Partitions, newESPPartition(rng))
+		}
+	case platform.BOOT_HYBRID:
+		if !hasBIOSBoot {
+			pt.Partitions = append(pt.Partitions, newBIOSBootPartition(rng))
+		}
+		if !hasESP {
+			pt.Partitions = append(pt.Partitions, newESPPartition(rng))
+		}
+	case platform.BOOT_NONE:
+		// nothing to do
+	default:
+		return fmt.Errorf("unknown boot mode: %v", bootMode)
+	}
+
+	return nil
+}
+
+func newBIOSBootPartition(rng *rand.Rand) Partition {
+	return Partition{
+		Size:     1 * MiB,
+		Type:     customPartitionTypeBIOSBoot,
+		Bootable: true,
+		UUID:     genUUID(rng),
+	}
+}
+
+func newESPPartition(rng *rand.Rand) Partition {
+	return Partition{
+		Size: 200 * MiB,
+		Type: customPartitionTypeESP,
+		UUID: genUUID(rng),
+		Payload: &Filesystem{
+			Type:       "vfat",
+			Mountpoint: "/boot/efi",
+			UUID:       genUUID(rng),
+		},
+	}
+}
+
+// growContainers grows every partition whose payload is an LVM volume group
+// or a btrfs volume so that it is at least as large as the sum of its
+// children's minimum sizes.
+func growContainers(pt *PartitionTable) {
+	for idx := range pt.Partitions {
+		part := &pt.Partitions[idx]
+		switch payload := part.Payload.(type) {
+		case *LVMVolumeGroup:
+			var sum uint64
+			for _, lv := range payload.LogicalVolumes {
+				sum += lv.Size
+			}
+			if sum > part.Size {
+				part.Size = sum
+			}
+		case *Btrfs:
+			// btrfs subvolumes share space dynamically; nothing to grow.
+			_ = payload
+		}
+	}
+}
+
+// genUUID deterministically derives a random UUID (v4 layout) from rng, so
+// that repeated manifest generation for the same seed produces the same
+// partition table.
+func genUUID(rng *rand.Rand) string {
+	var b [16]byte
+	_, _ = rng.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// genVGName derives a deterministic, short volume group name from rng for
+// customizations that don't specify one explicitly.
+func genVGName(rng *rand.Rand) string {
+	return fmt.Sprintf("vg-%s", genShortID(rng))
+}
+
+// genShortID returns a short random hex string derived from rng, suitable
+// for generating unique but deterministic names (VG names, LV names, ...).
+func genShortID(rng *rand.Rand) string {
+	var b [4]byte
+	_, _ = rng.Read(b[:])
+	return fmt.Sprintf("%x", b)
+}
+
+// wrapWithLUKS wraps payload in a LUKSContainer when enc requests
+// encryption, threading through the cipher, PBKDF, sector size, and label
+// parameters from the customization. It returns payload unchanged when enc
+// is nil.
+func wrapWithLUKS(payload PayloadEntity, enc *blueprint.EncryptionCustomization, rng *rand.Rand) (PayloadEntity, error) {
+	if enc == nil {
+		return payload, nil
+	}
+
+	if err := enc.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid encryption customization: %w", err)
+	}
+
+	return &LUKSContainer{
+		UUID:       genUUID(rng),
+		Label:      enc.Label,
+		Passphrase: enc.Passphrase,
+		Cipher:     enc.Cipher,
+		SectorSize: enc.SectorSize,
+		PBKDF: PBKDF{
+			Type:        enc.PBKDF.Type,
+			Memory:      enc.PBKDF.Memory,
+			Iterations:  enc.PBKDF.Iterations,
+			Parallelism: enc.PBKDF.Parallelism,
+		},
+		Payload: payload,
+	}, nil
+}
+
+// PBKDF holds the password-based key derivation function parameters for a
+// LUKS2 key slot, mirroring blueprint.PBKDFCustomization.
+type PBKDF struct {
+	Type        string
+	Memory      uint64
+	Iterations  uint64
+	Parallelism uint64
+}
@@ -0,0 +1,269 @@
+package disk
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/osbuild/images/pkg/blueprint"
+	"github.com/osbuild/images/pkg/platform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCustomPartitionTableInjectsBootAndRoot(t *testing.T) {
+	base := testPartitionTables["plain"]
+
+	/* #nosec G404 */
+	pt, err := NewCustomPartitionTable(&base, &blueprint.DiskCustomization{}, "plain", platform.BOOT_HYBRID, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+
+	var hasBIOSBoot, hasESP, hasBoot, hasRoot bool
+	for _, part := range pt.Partitions {
+		switch part.Type {
+		case customPartitionTypeBIOSBoot:
+			hasBIOSBoot = true
+		case customPartitionTypeESP:
+			hasESP = true
+		}
+		for _, mountpoint := range payloadMountpoints(part.Payload) {
+			switch mountpoint {
+			case "/boot":
+				hasBoot = true
+			case "/":
+				hasRoot = true
+			}
+		}
+	}
+
+	assert.True(t, hasBIOSBoot, "expected an auto-injected BIOS boot partition")
+	assert.True(t, hasESP, "expected an auto-injected ESP")
+	assert.True(t, hasBoot, "expected an auto-injected /boot partition")
+	assert.True(t, hasRoot, "expected an auto-injected / partition")
+}
+
+// TestNewCustomPartitionTableUserESPNotDuplicated is a regression test: a
+// user-supplied plain partition mounted at /boot/efi has no way to carry
+// the ESP GUID (PartitionCustomization has no field for it), so
+// ensureBootPartitions must recognize it by mountpoint or it will inject a
+// second ESP alongside the user's.
+func TestNewCustomPartitionTableUserESPNotDuplicated(t *testing.T) {
+	base := testPartitionTables["plain"]
+
+	custom := &blueprint.DiskCustomization{
+		Partitions: []blueprint.PartitionCustomization{
+			{
+				Type:    "plain",
+				MinSize: 200 * MiB,
+				FilesystemTypedCustomization: blueprint.FilesystemTypedCustomization{
+					Mountpoint: "/boot/efi",
+					FSType:     "vfat",
+				},
+			},
+		},
+	}
+
+	/* #nosec G404 */
+	pt, err := NewCustomPartitionTable(&base, custom, "plain", platform.BOOT_UEFI, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+
+	var espMountpoints int
+	for _, part := range pt.Partitions {
+		for _, mountpoint := range payloadMountpoints(part.Payload) {
+			if mountpoint == "/boot/efi" {
+				espMountpoints++
+			}
+		}
+	}
+
+	assert.Equal(t, 1, espMountpoints, "user-supplied ESP must not be duplicated")
+}
+
+func TestNewCustomPartitionTableGrowsLVMContainer(t *testing.T) {
+	base := testPartitionTables["plain"]
+
+	custom := &blueprint.DiskCustomization{
+		Partitions: []blueprint.PartitionCustomization{
+			{
+				Type:    "lvm",
+				MinSize: 1 * GiB,
+				VGCustomization: blueprint.VGCustomization{
+					LogicalVolumes: []blueprint.LVCustomization{
+						{
+							Name:    "data",
+							MinSize: 5 * GiB,
+							FilesystemTypedCustomization: blueprint.FilesystemTypedCustomization{
+								Mountpoint: "/data",
+								FSType:     "xfs",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	/* #nosec G404 */
+	pt, err := NewCustomPartitionTable(&base, custom, "plain", platform.BOOT_NONE, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+
+	var vgPart *Partition
+	for i, part := range pt.Partitions {
+		if _, ok := part.Payload.(*LVMVolumeGroup); ok {
+			vgPart = &pt.Partitions[i]
+		}
+	}
+	require.NotNil(t, vgPart, "expected an LVM partition")
+	assert.Equal(t, uint64(5*GiB), vgPart.Size, "container should grow to fit its logical volumes")
+}
+
+func TestNewCustomPartitionTableRejectsInvalidCustomization(t *testing.T) {
+	base := testPartitionTables["plain"]
+
+	custom := &blueprint.DiskCustomization{
+		Partitions: []blueprint.PartitionCustomization{
+			{
+				Type:    "plain",
+				MinSize: 1 * GiB,
+				FilesystemTypedCustomization: blueprint.FilesystemTypedCustomization{
+					Mountpoint: "/data",
+					FSType:     "xfs",
+					Encryption: &blueprint.EncryptionCustomization{
+						Type: "luks2",
+						// no passphrase: invalid
+					},
+				},
+			},
+		},
+	}
+
+	/* #nosec G404 */
+	_, err := NewCustomPartitionTable(&base, custom, "plain", platform.BOOT_NONE, rand.New(rand.NewSource(0)))
+	assert.Error(t, err)
+}
+
+func TestNewCustomPartitionTableDeterministicUUIDs(t *testing.T) {
+	base := testPartitionTables["plain"]
+	custom := &blueprint.DiskCustomization{}
+
+	/* #nosec G404 */
+	ptA, err := NewCustomPartitionTable(&base, custom, "plain", platform.BOOT_HYBRID, rand.New(rand.NewSource(42)))
+	require.NoError(t, err)
+
+	/* #nosec G404 */
+	ptB, err := NewCustomPartitionTable(&base, custom, "plain", platform.BOOT_HYBRID, rand.New(rand.NewSource(42)))
+	require.NoError(t, err)
+
+	require.Len(t, ptB.Partitions, len(ptA.Partitions))
+	for i := range ptA.Partitions {
+		assert.Equal(t, ptA.Partitions[i].UUID, ptB.Partitions[i].UUID, "same rng seed must produce the same UUIDs")
+	}
+}
+
+func TestNewCustomPartitionTableEncryptsPartition(t *testing.T) {
+	base := testPartitionTables["plain"]
+
+	custom := &blueprint.DiskCustomization{
+		Partitions: []blueprint.PartitionCustomization{
+			{
+				Type:    "plain",
+				MinSize: 1 * GiB,
+				FilesystemTypedCustomization: blueprint.FilesystemTypedCustomization{
+					Mountpoint: "/secret",
+					FSType:     "xfs",
+					Encryption: &blueprint.EncryptionCustomization{
+						Type:       "luks2",
+						Passphrase: "correct horse battery staple",
+						PBKDF: blueprint.PBKDFCustomization{
+							Type:        "argon2id",
+							Memory:      64 * 1024,
+							Iterations:  4,
+							Parallelism: 2,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	/* #nosec G404 */
+	pt, err := NewCustomPartitionTable(&base, custom, "plain", platform.BOOT_NONE, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+
+	var luks *LUKSContainer
+	for _, part := range pt.Partitions {
+		if c, ok := part.Payload.(*LUKSContainer); ok {
+			luks = c
+		}
+	}
+	require.NotNil(t, luks, "expected an encrypted partition")
+	assert.Equal(t, "correct horse battery staple", luks.Passphrase)
+	assert.Equal(t, uint64(64*1024), luks.PBKDF.Memory)
+	assert.Equal(t, uint64(4), luks.PBKDF.Iterations)
+	assert.Equal(t, uint64(2), luks.PBKDF.Parallelism)
+}
+
+// TestNewCustomPartitionTableAppliesRequiredMinSizeDefault is a regression
+// test: a user-supplied partition or logical volume at a required
+// mountpoint (/, /boot) that leaves MinSize unset must fall back to that
+// mountpoint's required minimum instead of ending up with a 0-byte size.
+// Since the mountpoint is already present in the customization,
+// collectMountpoints skips the auto-injection paths that would otherwise
+// supply a default.
+func TestNewCustomPartitionTableAppliesRequiredMinSizeDefault(t *testing.T) {
+	base := testPartitionTables["plain"]
+
+	custom := &blueprint.DiskCustomization{
+		Partitions: []blueprint.PartitionCustomization{
+			{
+				Type: "plain",
+				// MinSize intentionally left unset.
+				FilesystemTypedCustomization: blueprint.FilesystemTypedCustomization{
+					Mountpoint: "/boot",
+					FSType:     "xfs",
+				},
+			},
+			{
+				Type:    "lvm",
+				MinSize: 1 * GiB,
+				VGCustomization: blueprint.VGCustomization{
+					LogicalVolumes: []blueprint.LVCustomization{
+						{
+							Name: "root",
+							// MinSize intentionally left unset.
+							FilesystemTypedCustomization: blueprint.FilesystemTypedCustomization{
+								Mountpoint: "/",
+								FSType:     "xfs",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	/* #nosec G404 */
+	pt, err := NewCustomPartitionTable(&base, custom, "plain", platform.BOOT_NONE, rand.New(rand.NewSource(0)))
+	require.NoError(t, err)
+
+	var bootSize, rootSize uint64
+	for _, part := range pt.Partitions {
+		if fs, ok := part.Payload.(*Filesystem); ok && fs.Mountpoint == "/boot" {
+			bootSize = part.Size
+		}
+		if vg, ok := part.Payload.(*LVMVolumeGroup); ok {
+			for _, lv := range vg.LogicalVolumes {
+				if fs, ok := lv.Payload.(*Filesystem); ok && fs.Mountpoint == "/" {
+					rootSize = lv.Size
+				}
+			}
+		}
+	}
+
+	requiredBoot, ok := blueprint.RequiredMinSize("/boot")
+	require.True(t, ok)
+	requiredRoot, ok := blueprint.RequiredMinSize("/")
+	require.True(t, ok)
+
+	assert.Equal(t, requiredBoot, bootSize, "/boot with no MinSize must fall back to the required minimum")
+	assert.Equal(t, requiredRoot, rootSize, "/ with no MinSize must fall back to the required minimum")
+}
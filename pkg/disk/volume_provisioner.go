@@ -0,0 +1,118 @@
+package disk
+
+import (
+	"fmt"
+
+	"github.com/osbuild/images/pkg/blueprint"
+)
+
+// VolumeAssignment records which physical disk a [blueprint.VolumeCustomization]
+// was resolved to, and the final size (in bytes) it should occupy on that
+// disk.
+type VolumeAssignment struct {
+	Volume blueprint.VolumeCustomization
+	Disk   CandidateDisk
+	Size   uint64
+}
+
+// ResolveVolumes matches each volume against the given candidate disks using
+// its disk_selector expression, and returns a deterministic, stable
+// assignment of volumes to disks.
+//
+// Volumes are resolved in the order they appear in volumes; each one claims
+// the first candidate (in the order given) that satisfies its selector,
+// hasn't already been claimed by an earlier volume, and has enough room for
+// the volume's MinSize. A selector that matches no available disk with
+// enough free space is reported as a validation error rather than silently
+// dropped.
+//
+// Once every volume has a disk, volumes with Provisioning.Grow set are
+// resized to fill whatever space remains on their disk after all
+// fixed-size volumes sharing that disk have been accounted for.
+func ResolveVolumes(volumes []blueprint.VolumeCustomization, candidates []CandidateDisk) ([]VolumeAssignment, error) {
+	claimed := make(map[string]bool, len(candidates))
+	assignments := make([]VolumeAssignment, 0, len(volumes))
+
+	for _, vol := range volumes {
+		selector, err := ParseDiskSelector(vol.Provisioning.DiskSelector)
+		if err != nil {
+			return nil, fmt.Errorf("volume %q: %w", vol.Name, err)
+		}
+
+		minSize := vol.Provisioning.MinSize
+
+		disk, err := resolveOne(selector, candidates, claimed, minSize)
+		if err != nil {
+			return nil, fmt.Errorf("volume %q: %w", vol.Name, err)
+		}
+		claimed[disk.Path] = true
+
+		assignments = append(assignments, VolumeAssignment{
+			Volume: vol,
+			Disk:   *disk,
+			Size:   minSize,
+		})
+	}
+
+	if err := growVolumes(assignments); err != nil {
+		return nil, err
+	}
+
+	return assignments, nil
+}
+
+// resolveOne returns the first candidate disk (in order) that matches
+// selector, is not already present in claimed, and is large enough to hold
+// minSize.
+func resolveOne(selector *DiskSelector, candidates []CandidateDisk, claimed map[string]bool, minSize uint64) (*CandidateDisk, error) {
+	for i := range candidates {
+		candidate := &candidates[i]
+		if claimed[candidate.Path] {
+			continue
+		}
+		if minSize > candidate.Size {
+			continue
+		}
+		ok, err := selector.Matches(candidate)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return candidate, nil
+		}
+	}
+	return nil, fmt.Errorf("no available disk with at least %d bytes free matches selector %q", minSize, selector.String())
+}
+
+// growVolumes extends every volume with Provisioning.Grow set to fill the
+// remaining space on its assigned disk, after subtracting the fixed-size
+// volumes sharing that disk. ResolveVolumes assigns each disk to at most
+// one volume. If assignments holds more than one growing volume on the same
+// disk, each grows to the disk size minus the sizes of the other volumes on
+// it; the remaining space is not split between them.
+func growVolumes(assignments []VolumeAssignment) error {
+	usedByDisk := make(map[string]uint64, len(assignments))
+	for _, a := range assignments {
+		usedByDisk[a.Disk.Path] += a.Size
+	}
+
+	for i := range assignments {
+		a := &assignments[i]
+		used := usedByDisk[a.Disk.Path]
+		if used > a.Disk.Size {
+			// resolveOne only admits a volume onto a disk that has room for
+			// its MinSize, so this only fires if that invariant is ever
+			// broken; fail loudly instead of letting the subtraction below
+			// underflow.
+			return fmt.Errorf("volume %q: committed size %d bytes exceeds disk %q capacity of %d bytes", a.Volume.Name, used, a.Disk.Path, a.Disk.Size)
+		}
+		if !a.Volume.Provisioning.Grow {
+			continue
+		}
+		remaining := a.Disk.Size - used + a.Size
+		if remaining > a.Size {
+			a.Size = remaining
+		}
+	}
+	return nil
+}
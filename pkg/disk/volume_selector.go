@@ -0,0 +1,471 @@
+package disk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CandidateDisk describes a physical disk that a [blueprint.VolumeCustomization]
+// can be matched against by a disk_selector expression.
+type CandidateDisk struct {
+	// Path is the block device path (e.g. "/dev/nvme0n1"). Not itself
+	// selectable from an expression, but used to report resolutions.
+	Path string
+
+	// Size is the disk size in bytes (disk.size).
+	Size uint64
+
+	// Model is the disk's reported model string (disk.model).
+	Model string
+
+	// Transport is the disk's bus, e.g. "nvme", "sata", "scsi" (disk.transport).
+	Transport string
+
+	// Rotational is true for spinning disks, false for SSD/NVMe (disk.rotational).
+	Rotational bool
+
+	// System is true if the disk hosts the running system (e.g. the disk
+	// osbuild itself booted from) and should usually be avoided for data
+	// volumes (disk.system).
+	System bool
+
+	// WWN is the disk's World Wide Name, if any (disk.wwn).
+	WWN string
+}
+
+// diskSelectorFields are the field names that may appear after "disk." in a
+// selector expression, along with the Go kind of value they evaluate to.
+var diskSelectorFields = map[string]func(*CandidateDisk) any{
+	"size":       func(d *CandidateDisk) any { return d.Size },
+	"model":      func(d *CandidateDisk) any { return d.Model },
+	"transport":  func(d *CandidateDisk) any { return d.Transport },
+	"rotational": func(d *CandidateDisk) any { return d.Rotational },
+	"system":     func(d *CandidateDisk) any { return d.System },
+	"wwn":        func(d *CandidateDisk) any { return d.WWN },
+}
+
+// sizeUnits maps the size-literal suffixes accepted in selector expressions
+// to their multiplier in bytes. Decimal units (GB) and binary units (GiB)
+// are both accepted, matching common disk-vendor and OS conventions.
+var sizeUnits = []struct {
+	suffix     string
+	multiplier uint64
+}{
+	{"TiB", 1 << 40},
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"TB", 1_000_000_000_000},
+	{"GB", 1_000_000_000},
+	{"MB", 1_000_000},
+	{"KB", 1_000},
+}
+
+// DiskSelector is a parsed disk_selector expression that can be evaluated
+// against a [CandidateDisk] without re-parsing the source text each time.
+type DiskSelector struct {
+	src    string
+	tokens []selectorToken
+}
+
+// ParseDiskSelector parses a disk_selector expression. The grammar supports
+// "&&", "||", unary "!", the comparison operators "==", "!=", ">", ">=",
+// "<", "<=", parentheses, string literals ('...'), size literals (e.g.
+// "100GB"), and the disk.* fields listed on [CandidateDisk].
+func ParseDiskSelector(expr string) (*DiskSelector, error) {
+	tokens, err := tokenizeSelector(expr)
+	if err != nil {
+		return nil, fmt.Errorf("disk selector %q: %w", expr, err)
+	}
+	sel := &DiskSelector{src: expr, tokens: tokens}
+
+	// Parse once up front purely to validate the expression; Matches() reparses
+	// the token stream because the parser is a simple recursive-descent
+	// one-shot consumer.
+	p := &selectorParser{tokens: tokens}
+	if _, err := p.parseOr(); err != nil {
+		return nil, fmt.Errorf("disk selector %q: %w", expr, err)
+	}
+	if !p.atEnd() {
+		return nil, fmt.Errorf("disk selector %q: unexpected trailing input", expr)
+	}
+
+	return sel, nil
+}
+
+// Matches evaluates the selector against a candidate disk.
+func (s *DiskSelector) Matches(d *CandidateDisk) (bool, error) {
+	p := &selectorParser{tokens: s.tokens, disk: d}
+	v, err := p.parseOr()
+	if err != nil {
+		return false, fmt.Errorf("disk selector %q: %w", s.src, err)
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("disk selector %q: expression does not evaluate to a boolean", s.src)
+	}
+	return b, nil
+}
+
+// String returns the original, unparsed selector expression.
+func (s *DiskSelector) String() string {
+	return s.src
+}
+
+type selectorTokenKind int
+
+const (
+	tokIdent selectorTokenKind = iota
+	tokString
+	tokNumber
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type selectorToken struct {
+	kind selectorTokenKind
+	text string
+}
+
+func tokenizeSelector(expr string) ([]selectorToken, error) {
+	var tokens []selectorToken
+	runes := []rune(expr)
+	i := 0
+	for i < len(runes) {
+		c := runes[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			tokens = append(tokens, selectorToken{tokLParen, "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, selectorToken{tokRParen, ")"})
+			i++
+		case c == '!' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, selectorToken{tokOp, "!="})
+			i += 2
+		case c == '!':
+			tokens = append(tokens, selectorToken{tokOp, "!"})
+			i++
+		case c == '&' && i+1 < len(runes) && runes[i+1] == '&':
+			tokens = append(tokens, selectorToken{tokOp, "&&"})
+			i += 2
+		case c == '|' && i+1 < len(runes) && runes[i+1] == '|':
+			tokens = append(tokens, selectorToken{tokOp, "||"})
+			i += 2
+		case c == '=' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, selectorToken{tokOp, "=="})
+			i += 2
+		case c == '>' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, selectorToken{tokOp, ">="})
+			i += 2
+		case c == '<' && i+1 < len(runes) && runes[i+1] == '=':
+			tokens = append(tokens, selectorToken{tokOp, "<="})
+			i += 2
+		case c == '>':
+			tokens = append(tokens, selectorToken{tokOp, ">"})
+			i++
+		case c == '<':
+			tokens = append(tokens, selectorToken{tokOp, "<"})
+			i++
+		case c == '\'':
+			j := i + 1
+			for j < len(runes) && runes[j] != '\'' {
+				j++
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			tokens = append(tokens, selectorToken{tokString, string(runes[i+1 : j])})
+			i = j + 1
+		case isIdentStart(c):
+			j := i + 1
+			for j < len(runes) && (isIdentPart(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, selectorToken{tokIdent, string(runes[i:j])})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i + 1
+			for j < len(runes) && (runes[j] >= '0' && runes[j] <= '9' || runes[j] == '.' || isIdentPart(runes[j])) {
+				j++
+			}
+			tokens = append(tokens, selectorToken{tokNumber, string(runes[i:j])})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return tokens, nil
+}
+
+func isIdentStart(c rune) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c rune) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// selectorParser is a recursive-descent parser/evaluator for the selector
+// grammar. When disk is nil it only validates syntax; when set it evaluates
+// operands against the candidate disk's fields.
+type selectorParser struct {
+	tokens []selectorToken
+	pos    int
+	disk   *CandidateDisk
+}
+
+func (p *selectorParser) atEnd() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *selectorParser) peek() (selectorToken, bool) {
+	if p.atEnd() {
+		return selectorToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *selectorParser) consumeOp(op string) bool {
+	tok, ok := p.peek()
+	if !ok || tok.kind != tokOp || tok.text != op {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *selectorParser) parseOr() (any, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeOp("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		lb, lok := left.(bool)
+		rb, rok := right.(bool)
+		if !lok || !rok {
+			return nil, fmt.Errorf("'||' requires boolean operands")
+		}
+		left = lb || rb
+	}
+	return left, nil
+}
+
+func (p *selectorParser) parseAnd() (any, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeOp("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		lb, lok := left.(bool)
+		rb, rok := right.(bool)
+		if !lok || !rok {
+			return nil, fmt.Errorf("'&&' requires boolean operands")
+		}
+		left = lb && rb
+	}
+	return left, nil
+}
+
+func (p *selectorParser) parseUnary() (any, error) {
+	if p.consumeOp("!") {
+		v, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("'!' requires a boolean operand")
+		}
+		return !b, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *selectorParser) parseComparison() (any, error) {
+	left, err := p.parseGroupOrOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range []string{"==", "!=", ">=", "<=", ">", "<"} {
+		if p.consumeOp(op) {
+			right, err := p.parseGroupOrOperand()
+			if err != nil {
+				return nil, err
+			}
+			return compareValues(op, left, right)
+		}
+	}
+
+	return left, nil
+}
+
+func (p *selectorParser) parseGroupOrOperand() (any, error) {
+	if tok, ok := p.peek(); ok && tok.kind == tokLParen {
+		p.pos++
+		v, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok, ok := p.peek(); !ok || tok.kind != tokRParen {
+			return nil, fmt.Errorf("expected ')'")
+		}
+		p.pos++
+		return v, nil
+	}
+	if p.consumeOp("!") {
+		v, err := p.parseGroupOrOperand()
+		if err != nil {
+			return nil, err
+		}
+		b, ok := v.(bool)
+		if !ok {
+			return nil, fmt.Errorf("'!' requires a boolean operand")
+		}
+		return !b, nil
+	}
+	return p.parseOperand()
+}
+
+func (p *selectorParser) parseOperand() (any, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	switch tok.kind {
+	case tokString:
+		p.pos++
+		return tok.text, nil
+	case tokNumber:
+		p.pos++
+		return parseSizeLiteral(tok.text)
+	case tokIdent:
+		p.pos++
+		if tok.text == "true" {
+			return true, nil
+		}
+		if tok.text == "false" {
+			return false, nil
+		}
+		return p.resolveField(tok.text)
+	default:
+		return nil, fmt.Errorf("unexpected token %q", tok.text)
+	}
+}
+
+func (p *selectorParser) resolveField(name string) (any, error) {
+	const prefix = "disk."
+	if !strings.HasPrefix(name, prefix) {
+		return nil, fmt.Errorf("unknown identifier %q (expected a disk.* field)", name)
+	}
+	field := strings.TrimPrefix(name, prefix)
+	accessor, ok := diskSelectorFields[field]
+	if !ok {
+		return nil, fmt.Errorf("unknown disk field %q", field)
+	}
+	if p.disk == nil {
+		// Syntax-only validation: return a zero value of the right kind so
+		// that type checks in comparisons/logical ops still make sense.
+		return accessor(&CandidateDisk{}), nil
+	}
+	return accessor(p.disk), nil
+}
+
+func parseSizeLiteral(text string) (uint64, error) {
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(text, unit.suffix) {
+			numPart := strings.TrimSuffix(text, unit.suffix)
+			n, err := strconv.ParseFloat(numPart, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid size literal %q: %w", text, err)
+			}
+			return uint64(n * float64(unit.multiplier)), nil
+		}
+	}
+	n, err := strconv.ParseUint(text, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid numeric literal %q: %w", text, err)
+	}
+	return n, nil
+}
+
+func compareValues(op string, left, right any) (bool, error) {
+	// numeric operands (size literals and disk.size) are always uint64;
+	// operands of mixed kinds fall through to the error below
+	if ln, lok := asUint64(left); lok {
+		if rn, rok := asUint64(right); rok {
+			return compareUint64(op, ln, rn)
+		}
+	}
+	if ls, lok := left.(string); lok {
+		if rs, rok := right.(string); rok {
+			return compareString(op, ls, rs)
+		}
+	}
+	if lb, lok := left.(bool); lok {
+		if rb, rok := right.(bool); rok {
+			return compareBool(op, lb, rb)
+		}
+	}
+	return false, fmt.Errorf("cannot compare %T with %T", left, right)
+}
+
+func asUint64(v any) (uint64, bool) {
+	n, ok := v.(uint64)
+	return n, ok
+}
+
+func compareUint64(op string, l, r uint64) (bool, error) {
+	switch op {
+	case "==":
+		return l == r, nil
+	case "!=":
+		return l != r, nil
+	case ">":
+		return l > r, nil
+	case ">=":
+		return l >= r, nil
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	default:
+		return false, fmt.Errorf("unsupported operator %q for numeric operands", op)
+	}
+}
+
+func compareString(op string, l, r string) (bool, error) {
+	switch op {
+	case "==":
+		return l == r, nil
+	case "!=":
+		return l != r, nil
+	default:
+		return false, fmt.Errorf("unsupported operator %q for string operands", op)
+	}
+}
+
+func compareBool(op string, l, r bool) (bool, error) {
+	switch op {
+	case "==":
+		return l == r, nil
+	case "!=":
+		return l != r, nil
+	default:
+		return false, fmt.Errorf("unsupported operator %q for boolean operands", op)
+	}
+}
@@ -0,0 +1,144 @@
+package disk
+
+import (
+	"testing"
+
+	"github.com/osbuild/images/pkg/blueprint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskSelectorMatches(t *testing.T) {
+	nvmeData := CandidateDisk{
+		Path:      "/dev/nvme0n1",
+		Size:      200 * GiB,
+		Model:     "Samsung SSD 980",
+		Transport: "nvme",
+		System:    false,
+	}
+	systemDisk := CandidateDisk{
+		Path:      "/dev/sda",
+		Size:      500 * GiB,
+		Transport: "sata",
+		System:    true,
+	}
+	smallNvme := CandidateDisk{
+		Path:      "/dev/nvme1n1",
+		Size:      50 * GiB,
+		Transport: "nvme",
+	}
+
+	type testCase struct {
+		expr     string
+		disk     CandidateDisk
+		expected bool
+	}
+
+	testCases := []testCase{
+		{"disk.size > 100GB && disk.transport == 'nvme' && !disk.system", nvmeData, true},
+		{"disk.size > 100GB && disk.transport == 'nvme' && !disk.system", smallNvme, false},
+		{"disk.size > 100GB && disk.transport == 'nvme' && !disk.system", systemDisk, false},
+		{"disk.transport == 'sata' || disk.transport == 'nvme'", smallNvme, true},
+		{"disk.system", systemDisk, true},
+		{"!disk.system", systemDisk, false},
+		{"disk.size >= 200GiB", nvmeData, true},
+		{"disk.model == 'Samsung SSD 980'", nvmeData, true},
+	}
+
+	for _, tc := range testCases {
+		selector, err := ParseDiskSelector(tc.expr)
+		require.NoError(t, err)
+
+		got, err := selector.Matches(&tc.disk)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, got, "expr %q against %+v", tc.expr, tc.disk)
+	}
+}
+
+func TestDiskSelectorInvalid(t *testing.T) {
+	_, err := ParseDiskSelector("disk.size >")
+	assert.Error(t, err)
+
+	_, err = ParseDiskSelector("disk.bogus == 'x'")
+	assert.Error(t, err)
+
+	_, err = ParseDiskSelector("disk.size")
+	selector, parseErr := ParseDiskSelector("disk.size")
+	require.NoError(t, parseErr)
+	_, err = selector.Matches(&CandidateDisk{})
+	assert.Error(t, err)
+}
+
+func TestResolveVolumesDeterministic(t *testing.T) {
+	candidates := []CandidateDisk{
+		{Path: "/dev/sda", Size: 500 * GiB, Transport: "sata", System: true},
+		{Path: "/dev/nvme0n1", Size: 200 * GiB, Transport: "nvme"},
+		{Path: "/dev/nvme1n1", Size: 100 * GiB, Transport: "nvme"},
+	}
+
+	volumes := []blueprint.VolumeCustomization{
+		{
+			Name: "data",
+			Provisioning: blueprint.ProvisioningCustomization{
+				DiskSelector: "disk.transport == 'nvme' && !disk.system",
+				MinSize:      50 * GiB,
+			},
+			Filesystem: blueprint.FilesystemTypedCustomization{Mountpoint: "/var/data", FSType: "xfs"},
+		},
+		{
+			Name: "scratch",
+			Provisioning: blueprint.ProvisioningCustomization{
+				DiskSelector: "disk.transport == 'nvme' && !disk.system",
+				MinSize:      10 * GiB,
+				Grow:         true,
+			},
+			Filesystem: blueprint.FilesystemTypedCustomization{Mountpoint: "/var/scratch", FSType: "xfs"},
+		},
+	}
+
+	assignments, err := ResolveVolumes(volumes, candidates)
+	require.NoError(t, err)
+	require.Len(t, assignments, 2)
+
+	assert.Equal(t, "/dev/nvme0n1", assignments[0].Disk.Path)
+	assert.Equal(t, uint64(50*GiB), assignments[0].Size)
+
+	assert.Equal(t, "/dev/nvme1n1", assignments[1].Disk.Path)
+	assert.Equal(t, uint64(100*GiB), assignments[1].Size)
+}
+
+func TestResolveVolumesRejectsOversizedVolume(t *testing.T) {
+	candidates := []CandidateDisk{
+		{Path: "/dev/nvme0n1", Size: 50 * GiB, Transport: "nvme"},
+	}
+	volumes := []blueprint.VolumeCustomization{
+		{
+			Name: "data",
+			Provisioning: blueprint.ProvisioningCustomization{
+				DiskSelector: "disk.transport == 'nvme'",
+				MinSize:      100 * GiB,
+			},
+			Filesystem: blueprint.FilesystemTypedCustomization{Mountpoint: "/var/data", FSType: "xfs"},
+		},
+	}
+
+	_, err := ResolveVolumes(volumes, candidates)
+	assert.Error(t, err)
+}
+
+func TestResolveVolumesUnmatchedSelector(t *testing.T) {
+	candidates := []CandidateDisk{
+		{Path: "/dev/sda", Size: 500 * GiB, Transport: "sata"},
+	}
+	volumes := []blueprint.VolumeCustomization{
+		{
+			Name: "data",
+			Provisioning: blueprint.ProvisioningCustomization{
+				DiskSelector: "disk.transport == 'nvme'",
+			},
+		},
+	}
+
+	_, err := ResolveVolumes(volumes, candidates)
+	assert.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package sbom
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/spdx/tools-golang/spdx"
+)
+
+// NewDocumentFromCycloneDXJSON creates a new SBOM Document from CycloneDX raw JSON data.
+func NewDocumentFromCycloneDXJSON(data []byte) (*Document, error) {
+	bom := new(cyclonedx.BOM)
+	decoder := cyclonedx.NewBOMDecoder(bytes.NewReader(data), cyclonedx.BOMFileFormatJSON)
+	if err := decoder.Decode(bom); err != nil {
+		return nil, fmt.Errorf("failed to read CycloneDX JSON: %w", err)
+	}
+	return newDocument(bom)
+}
+
+// ToCycloneDXJSON writes the SBOM Document to the writer in CycloneDX JSON format.
+func (d *Document) ToCycloneDXJSON(w io.Writer) error {
+	bom, err := d.ToCycloneDXDocument()
+	if err != nil {
+		return err
+	}
+	encoder := cyclonedx.NewBOMEncoder(w, cyclonedx.BOMFileFormatJSON)
+	encoder.SetPretty(true)
+	if err := encoder.Encode(bom); err != nil {
+		return fmt.Errorf("failed to write CycloneDX JSON: %w", err)
+	}
+	return nil
+}
+
+// ToCycloneDXDocument converts the SBOM Document to a CycloneDX BOM, converting
+// from the underlying standard if necessary.
+func (d *Document) ToCycloneDXDocument() (*cyclonedx.BOM, error) {
+	switch d.docType {
+	case StandardTypeCycloneDX:
+		return d.document.(*cyclonedx.BOM), nil
+	case StandardTypeSpdx:
+		return spdxToCycloneDX(d.document.(*spdx.Document))
+	default:
+		return nil, fmt.Errorf("conversion to CycloneDX document not supported for document type: %s", d.docType)
+	}
+}
+
+// spdxToCycloneDX converts an SPDX document into a CycloneDX BOM, mapping
+// SPDX packages to CycloneDX components.
+func spdxToCycloneDX(doc *spdx.Document) (*cyclonedx.BOM, error) {
+	bom := cyclonedx.NewBOM()
+	bom.SerialNumber = fmt.Sprintf("urn:spdx:%s", doc.SPDXIdentifier)
+
+	components := make([]cyclonedx.Component, 0, len(doc.Packages))
+	for _, pkg := range doc.Packages {
+		component := cyclonedx.Component{
+			Type:    cyclonedx.ComponentTypeLibrary,
+			BOMRef:  string(pkg.PackageSPDXIdentifier),
+			Name:    pkg.PackageName,
+			Version: pkg.PackageVersion,
+		}
+		if pkg.PackageLicenseDeclared != "" && pkg.PackageLicenseDeclared != "NOASSERTION" {
+			component.Licenses = &cyclonedx.Licenses{
+				{Expression: pkg.PackageLicenseDeclared},
+			}
+		}
+		for _, ref := range pkg.PackageExternalReferences {
+			if ref.RefType == "purl" {
+				component.PackageURL = ref.Locator
+			}
+		}
+		components = append(components, component)
+	}
+	bom.Components = &components
+
+	return bom, nil
+}
+
+// cycloneDXToSpdx converts a CycloneDX BOM into an SPDX document, mapping
+// CycloneDX components to SPDX packages.
+func cycloneDXToSpdx(bom *cyclonedx.BOM) (*spdx.Document, error) {
+	doc := &spdx.Document{
+		SPDXVersion:       spdx.Version,
+		DataLicense:       spdx.DataLicense,
+		SPDXIdentifier:    "DOCUMENT",
+		DocumentName:      "cyclonedx-converted",
+		DocumentNamespace: bom.SerialNumber,
+	}
+
+	if bom.Components == nil {
+		return doc, nil
+	}
+
+	packages := make([]*spdx.Package, 0, len(*bom.Components))
+	for _, component := range *bom.Components {
+		pkg := &spdx.Package{
+			PackageSPDXIdentifier:   spdx.ElementID(component.BOMRef),
+			PackageName:             component.Name,
+			PackageVersion:          component.Version,
+			PackageDownloadLocation: "NOASSERTION",
+		}
+		if component.Licenses != nil {
+			for _, lic := range *component.Licenses {
+				if lic.Expression != "" {
+					pkg.PackageLicenseDeclared = lic.Expression
+					break
+				}
+			}
+		}
+		if pkg.PackageLicenseDeclared == "" {
+			pkg.PackageLicenseDeclared = "NOASSERTION"
+		}
+		if component.PackageURL != "" {
+			pkg.PackageExternalReferences = append(pkg.PackageExternalReferences, &spdx.PackageExternalReference{
+				Category: "PACKAGE-MANAGER",
+				RefType:  "purl",
+				Locator:  component.PackageURL,
+			})
+		}
+		packages = append(packages, pkg)
+	}
+	doc.Packages = packages
+
+	return doc, nil
+}
@@ -0,0 +1,76 @@
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/CycloneDX/cyclonedx-go"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCycloneDXJSONDocument = "./test/example.cdx.json"
+
+// testingCycloneDXJSONRawDocument loads testCycloneDXJSONDocument and returns the raw JSON data.
+func testingCycloneDXJSONRawDocument() []byte {
+	data, err := os.ReadFile(testCycloneDXJSONDocument)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func TestNewDocumentFromCycloneDXJSON(t *testing.T) {
+	data := testingCycloneDXJSONRawDocument()
+	doc, err := NewDocumentFromCycloneDXJSON(data)
+	assert.NoError(t, err)
+	assert.NotNil(t, doc)
+
+	assert.Equal(t, StandardTypeCycloneDX, doc.docType)
+	assert.IsType(t, &cyclonedx.BOM{}, doc.document)
+}
+
+func TestToCycloneDXJSON(t *testing.T) {
+	data := testingCycloneDXJSONRawDocument()
+	doc, err := NewDocumentFromCycloneDXJSON(data)
+	assert.NoError(t, err)
+	assert.NotNil(t, doc)
+
+	writter := bytes.NewBuffer(nil)
+	err = doc.ToCycloneDXJSON(writter)
+	assert.NoError(t, err)
+
+	// we can't compare the raw JSON data because the order of the elements may change
+	// so compare unmarshaled interface{} objects instead
+	var expectedBOM interface{}
+	var gotBOM interface{}
+
+	err = json.Unmarshal(data, &expectedBOM)
+	assert.NoError(t, err)
+	err = json.Unmarshal(writter.Bytes(), &gotBOM)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedBOM, gotBOM)
+}
+
+func TestSpdxToCycloneDXConversion(t *testing.T) {
+	spdxData := testingSpdxJSONRawDocument()
+	doc, err := NewDocumentFromSpdxJSON(spdxData)
+	assert.NoError(t, err)
+	assert.NotNil(t, doc)
+
+	bom, err := doc.ToCycloneDXDocument()
+	assert.NoError(t, err)
+	assert.NotNil(t, bom)
+}
+
+func TestCycloneDXToSpdxConversion(t *testing.T) {
+	cdxData := testingCycloneDXJSONRawDocument()
+	doc, err := NewDocumentFromCycloneDXJSON(cdxData)
+	assert.NoError(t, err)
+	assert.NotNil(t, doc)
+
+	spdxDoc, err := doc.ToSpdxDocument()
+	assert.NoError(t, err)
+	assert.NotNil(t, spdxDoc)
+}
@@ -3,6 +3,7 @@ package sbom
 import (
 	"fmt"
 
+	"github.com/CycloneDX/cyclonedx-go"
 	"github.com/spdx/tools-golang/spdx"
 )
 
@@ -11,6 +12,7 @@ type StandardType uint64
 const (
 	StandardTypeNone StandardType = iota
 	StandardTypeSpdx
+	StandardTypeCycloneDX
 )
 
 func (t StandardType) String() string {
@@ -19,6 +21,8 @@ func (t StandardType) String() string {
 		return "none"
 	case StandardTypeSpdx:
 		return "spdx"
+	case StandardTypeCycloneDX:
+		return "cyclonedx"
 	default:
 		panic("invalid standard type")
 	}
@@ -38,6 +42,8 @@ func newDocument(d interface{}) (*Document, error) {
 	switch d.(type) {
 	case *spdx.Document:
 		docType = StandardTypeSpdx
+	case *cyclonedx.BOM:
+		docType = StandardTypeCycloneDX
 	default:
 		return nil, fmt.Errorf("unsupported SBOM document type: %T", d)
 	}
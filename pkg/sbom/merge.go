@@ -0,0 +1,141 @@
+package sbom
+
+import (
+	"fmt"
+
+	"github.com/spdx/tools-golang/common"
+	"github.com/spdx/tools-golang/spdx"
+)
+
+// PackageConflictError is returned by [Document.Merge] when the same
+// package (identified by NEVRA) appears in both documents with different
+// metadata, e.g. mismatched checksums pointing at different underlying
+// binaries.
+type PackageConflictError struct {
+	// SpdxIDs lists the conflicting packages' SPDX identifiers, one from
+	// each document, in the order (receiver, other).
+	SpdxIDs []string
+}
+
+func (e *PackageConflictError) Error() string {
+	return fmt.Sprintf("conflicting package metadata for SPDX IDs %v", e.SpdxIDs)
+}
+
+// Merge unions other into d: Packages, Files, Relationships, and
+// OtherLicenses are combined, deduplicating packages that represent the
+// same NEVRA (name-version-release.arch) and rewriting other's
+// relationships to point at the surviving SPDX ID when two packages are
+// merged into one. If the same NEVRA appears in both documents with
+// different checksums, Merge returns a *PackageConflictError rather than
+// guessing which one is authoritative.
+//
+// Both documents are converted to their SPDX representation for the
+// duration of the merge (via [Document.ToSpdxDocument]), and the result is
+// stored back into d as an SPDX-backed document.
+func (d *Document) Merge(other *Document) error {
+	base, err := d.ToSpdxDocument()
+	if err != nil {
+		return fmt.Errorf("cannot merge: %w", err)
+	}
+	incoming, err := other.ToSpdxDocument()
+	if err != nil {
+		return fmt.Errorf("cannot merge: %w", err)
+	}
+
+	merged, err := mergeSpdxDocuments(base, incoming)
+	if err != nil {
+		return err
+	}
+
+	d.docType = StandardTypeSpdx
+	d.document = merged
+	return nil
+}
+
+func mergeSpdxDocuments(base, incoming *spdx.Document) (*spdx.Document, error) {
+	byNEVRA := make(map[string]*spdx.Package, len(base.Packages))
+	byID := make(map[spdx.ElementID]*spdx.Package, len(base.Packages))
+	for _, pkg := range base.Packages {
+		byNEVRA[spdxPackageNEVRA(pkg)] = pkg
+		byID[pkg.PackageSPDXIdentifier] = pkg
+	}
+
+	// idRemap tracks, for every package ID in `incoming`, which ID it
+	// should be referenced by in the merged document (itself, unless it
+	// was deduplicated against an existing package or renamed to dodge an
+	// ID collision with an unrelated package).
+	idRemap := make(map[spdx.ElementID]spdx.ElementID, len(incoming.Packages))
+
+	merged := *base
+	merged.Packages = append([]*spdx.Package{}, base.Packages...)
+	merged.Files = append([]*spdx.File{}, base.Files...)
+	merged.OtherLicenses = append([]*spdx.OtherLicense{}, base.OtherLicenses...)
+
+	for _, pkg := range incoming.Packages {
+		nevra := spdxPackageNEVRA(pkg)
+		if existing, ok := byNEVRA[nevra]; ok {
+			if !checksumsEqual(existing.PackageChecksums, pkg.PackageChecksums) {
+				return nil, &PackageConflictError{
+					SpdxIDs: []string{string(existing.PackageSPDXIdentifier), string(pkg.PackageSPDXIdentifier)},
+				}
+			}
+			// same package, already present: drop the duplicate and point
+			// any relationships at it to the surviving ID.
+			idRemap[pkg.PackageSPDXIdentifier] = existing.PackageSPDXIdentifier
+			continue
+		}
+
+		newID := pkg.PackageSPDXIdentifier
+		if _, collision := byID[newID]; collision {
+			newID = spdx.ElementID(fmt.Sprintf("%s-merged", newID))
+		}
+
+		pkgCopy := *pkg
+		pkgCopy.PackageSPDXIdentifier = newID
+		idRemap[pkg.PackageSPDXIdentifier] = newID
+		byNEVRA[nevra] = &pkgCopy
+		byID[newID] = &pkgCopy
+		merged.Packages = append(merged.Packages, &pkgCopy)
+	}
+
+	merged.Files = append(merged.Files, incoming.Files...)
+	merged.OtherLicenses = append(merged.OtherLicenses, incoming.OtherLicenses...)
+
+	merged.Relationships = append([]*spdx.Relationship{}, base.Relationships...)
+	for _, rel := range incoming.Relationships {
+		relCopy := *rel
+		relCopy.RefA.ElementRefID = remapElementID(rel.RefA.ElementRefID, idRemap)
+		relCopy.RefB.ElementRefID = remapElementID(rel.RefB.ElementRefID, idRemap)
+		merged.Relationships = append(merged.Relationships, &relCopy)
+	}
+
+	return &merged, nil
+}
+
+func remapElementID(id spdx.ElementID, idRemap map[spdx.ElementID]spdx.ElementID) spdx.ElementID {
+	if newID, ok := idRemap[id]; ok {
+		return newID
+	}
+	return id
+}
+
+// spdxPackageNEVRA returns the name-version-release.arch identity used to
+// dedup packages across documents. This relies on PackageVersion already
+// carrying the architecture (see packageEVRA in packagesets.go); a
+// PackageVersion that only holds version-release would collide multilib
+// packages like glibc.x86_64 and glibc.i686.
+func spdxPackageNEVRA(pkg *spdx.Package) string {
+	return fmt.Sprintf("%s-%s", pkg.PackageName, pkg.PackageVersion)
+}
+
+func checksumsEqual(a, b []common.Checksum) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Algorithm != b[i].Algorithm || a[i].Value != b[i].Value {
+			return false
+		}
+	}
+	return true
+}
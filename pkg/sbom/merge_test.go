@@ -0,0 +1,103 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/spdx/tools-golang/common"
+	"github.com/spdx/tools-golang/spdx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSpdxDoc(pkgs ...*spdx.Package) *Document {
+	doc := &spdx.Document{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXIdentifier:    "DOCUMENT",
+		DocumentName:      "test",
+		DocumentNamespace: "urn:test",
+		Packages:          pkgs,
+	}
+	for _, pkg := range pkgs {
+		doc.Relationships = append(doc.Relationships, &spdx.Relationship{
+			RefA:         common.MakeDocElementID("", "DOCUMENT"),
+			RefB:         common.MakeDocElementID("", string(pkg.PackageSPDXIdentifier)),
+			Relationship: "CONTAINS",
+		})
+	}
+
+	d, err := newDocument(doc)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestMergeDeduplicatesSamePackage(t *testing.T) {
+	checksum := []common.Checksum{{Algorithm: common.SHA256, Value: "abc123"}}
+
+	a := newTestSpdxDoc(&spdx.Package{
+		PackageSPDXIdentifier:   "Package-1",
+		PackageName:             "bash",
+		PackageVersion:          "5.2-1.fc38",
+		PackageDownloadLocation: "NOASSERTION",
+		PackageChecksums:        checksum,
+	})
+	b := newTestSpdxDoc(&spdx.Package{
+		PackageSPDXIdentifier:   "Package-1",
+		PackageName:             "bash",
+		PackageVersion:          "5.2-1.fc38",
+		PackageDownloadLocation: "NOASSERTION",
+		PackageChecksums:        checksum,
+	})
+
+	require.NoError(t, a.Merge(b))
+
+	doc, err := a.ToSpdxDocument()
+	require.NoError(t, err)
+	assert.Len(t, doc.Packages, 1)
+}
+
+func TestMergeConflictingChecksums(t *testing.T) {
+	a := newTestSpdxDoc(&spdx.Package{
+		PackageSPDXIdentifier:   "Package-1",
+		PackageName:             "bash",
+		PackageVersion:          "5.2-1.fc38",
+		PackageDownloadLocation: "NOASSERTION",
+		PackageChecksums:        []common.Checksum{{Algorithm: common.SHA256, Value: "abc123"}},
+	})
+	b := newTestSpdxDoc(&spdx.Package{
+		PackageSPDXIdentifier:   "Package-1",
+		PackageName:             "bash",
+		PackageVersion:          "5.2-1.fc38",
+		PackageDownloadLocation: "NOASSERTION",
+		PackageChecksums:        []common.Checksum{{Algorithm: common.SHA256, Value: "def456"}},
+	})
+
+	err := a.Merge(b)
+	require.Error(t, err)
+	var conflictErr *PackageConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+}
+
+func TestMergeDistinctPackages(t *testing.T) {
+	a := newTestSpdxDoc(&spdx.Package{
+		PackageSPDXIdentifier:   "Package-1",
+		PackageName:             "bash",
+		PackageVersion:          "5.2-1.fc38",
+		PackageDownloadLocation: "NOASSERTION",
+	})
+	b := newTestSpdxDoc(&spdx.Package{
+		PackageSPDXIdentifier:   "Package-1",
+		PackageName:             "coreutils",
+		PackageVersion:          "9.1-1.fc38",
+		PackageDownloadLocation: "NOASSERTION",
+	})
+
+	require.NoError(t, a.Merge(b))
+
+	doc, err := a.ToSpdxDocument()
+	require.NoError(t, err)
+	require.Len(t, doc.Packages, 2)
+	assert.NotEqual(t, doc.Packages[0].PackageSPDXIdentifier, doc.Packages[1].PackageSPDXIdentifier)
+}
@@ -0,0 +1,162 @@
+package sbom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/osbuild/images/pkg/rpmmd"
+	"github.com/spdx/tools-golang/common"
+	"github.com/spdx/tools-golang/spdx"
+)
+
+// DocumentMetadata carries the identifying information for a document built
+// by [BuildFromPackageSets]: what the root package represents and who
+// produced the document.
+type DocumentMetadata struct {
+	// Name of the image (or other artifact) the document describes. Used
+	// both as the document name and as the name of the root package.
+	Name string
+
+	// Namespace is the SPDX document namespace, a URI that uniquely
+	// identifies this document.
+	Namespace string
+
+	// Creator identifies the tool that produced the document (e.g.
+	// "osbuild-images").
+	Creator string
+}
+
+const rootPackageID = spdx.ElementID("image")
+
+// BuildFromPackageSets converts a set of resolved package-set chains (as
+// produced by running an image type's manifest through depsolving, see
+// cmd/osbuild-package-sets) into a valid SPDX 2.3 [Document]. The document
+// has a single root package (which the document DESCRIBES) with a CONTAINS
+// relationship to every RPM across all chains, each carrying a PURL
+// external reference and its declared license.
+//
+// Packages that appear in more than one chain (the same NEVRA) are only
+// included once.
+func BuildFromPackageSets(chains map[string][]rpmmd.PackageSpec, meta DocumentMetadata) (*Document, error) {
+	doc := &spdx.Document{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXIdentifier:    "DOCUMENT",
+		DocumentName:      meta.Name,
+		DocumentNamespace: meta.Namespace,
+		CreationInfo: &spdx.CreationInfo{
+			Creators: []common.Creator{
+				{CreatorType: "Tool", Creator: meta.Creator},
+			},
+			Created: time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+
+	rootPkg := &spdx.Package{
+		PackageSPDXIdentifier:   rootPackageID,
+		PackageName:             meta.Name,
+		PackageDownloadLocation: "NOASSERTION",
+		PackageLicenseDeclared:  "NOASSERTION",
+	}
+	doc.Packages = append(doc.Packages, rootPkg)
+	doc.Relationships = append(doc.Relationships, &spdx.Relationship{
+		RefA:         common.MakeDocElementID("", "DOCUMENT"),
+		RefB:         common.MakeDocElementID("", string(rootPackageID)),
+		Relationship: "DESCRIBES",
+	})
+
+	seenNEVRAs := make(map[string]bool)
+	pkgIdx := 0
+	// sort chain names so the resulting document is stable across runs with
+	// the same input
+	for _, chainName := range sortedKeys(chains) {
+		for _, spec := range chains[chainName] {
+			nevra := packageNEVRA(spec)
+			if seenNEVRAs[nevra] {
+				continue
+			}
+			seenNEVRAs[nevra] = true
+			pkgIdx++
+
+			id := spdx.ElementID(fmt.Sprintf("Package-%d", pkgIdx))
+			license := spec.License
+			if license == "" {
+				license = "NOASSERTION"
+			}
+
+			pkg := &spdx.Package{
+				PackageSPDXIdentifier:   id,
+				PackageName:             spec.Name,
+				PackageVersion:          packageEVRA(spec),
+				PackageDownloadLocation: "NOASSERTION",
+				PackageLicenseDeclared:  license,
+				PackageExternalReferences: []*spdx.PackageExternalReference{
+					{
+						Category: "PACKAGE-MANAGER",
+						RefType:  "purl",
+						Locator:  packagePURL(spec),
+					},
+				},
+				PackageChecksums: packageChecksums(spec),
+			}
+			doc.Packages = append(doc.Packages, pkg)
+
+			doc.Relationships = append(doc.Relationships, &spdx.Relationship{
+				RefA:         common.MakeDocElementID("", string(rootPackageID)),
+				RefB:         common.MakeDocElementID("", string(id)),
+				Relationship: "CONTAINS",
+			})
+		}
+	}
+
+	return newDocument(doc)
+}
+
+func packageEVR(spec rpmmd.PackageSpec) string {
+	return fmt.Sprintf("%s-%s", spec.Version, spec.Release)
+}
+
+// packageEVRA returns "version-release.arch", the value stored in
+// PackageVersion. Keeping the architecture as part of PackageVersion (rather
+// than dropping it) is what lets [spdxPackageNEVRA] in merge.go tell apart
+// multilib packages (e.g. glibc.x86_64 vs glibc.i686) that otherwise share
+// name, version, and release.
+func packageEVRA(spec rpmmd.PackageSpec) string {
+	return fmt.Sprintf("%s.%s", packageEVR(spec), spec.Arch)
+}
+
+func packageNEVRA(spec rpmmd.PackageSpec) string {
+	return fmt.Sprintf("%s-%s", spec.Name, packageEVRA(spec))
+}
+
+func packagePURL(spec rpmmd.PackageSpec) string {
+	return fmt.Sprintf("pkg:rpm/%s@%s-%s?arch=%s", spec.Name, spec.Version, spec.Release, spec.Arch)
+}
+
+// packageChecksums converts spec's checksum (e.g. "sha256:abc123...") into
+// the single-element []common.Checksum SPDX expects. Packages without a
+// checksum (e.g. resolved from a repo that doesn't advertise one) get no
+// PackageChecksums, same as leaving the field unset.
+func packageChecksums(spec rpmmd.PackageSpec) []common.Checksum {
+	if spec.Checksum == "" {
+		return nil
+	}
+
+	algo, value, ok := strings.Cut(spec.Checksum, ":")
+	if !ok {
+		return []common.Checksum{{Algorithm: common.SHA256, Value: spec.Checksum}}
+	}
+
+	return []common.Checksum{{Algorithm: common.ChecksumAlgorithm(strings.ToUpper(algo)), Value: value}}
+}
+
+func sortedKeys(chains map[string][]rpmmd.PackageSpec) []string {
+	keys := make([]string, 0, len(chains))
+	for k := range chains {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,119 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/osbuild/images/pkg/rpmmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMeta(name string) DocumentMetadata {
+	return DocumentMetadata{
+		Name:      name,
+		Namespace: "urn:test:" + name,
+		Creator:   "test",
+	}
+}
+
+func TestBuildFromPackageSetsDescribesRoot(t *testing.T) {
+	doc, err := BuildFromPackageSets(map[string][]rpmmd.PackageSpec{
+		"build": {
+			{Name: "bash", Version: "5.2", Release: "1.fc38", Arch: "x86_64", License: "GPLv3+", Checksum: "sha256:abc123"},
+		},
+	}, testMeta("image"))
+	require.NoError(t, err)
+
+	spdxDoc, err := doc.ToSpdxDocument()
+	require.NoError(t, err)
+
+	require.Len(t, spdxDoc.Relationships, 2)
+	describes := spdxDoc.Relationships[0]
+	assert.Equal(t, "DESCRIBES", describes.Relationship)
+	assert.Equal(t, "DOCUMENT", string(describes.RefA.ElementRefID))
+	assert.Equal(t, string(rootPackageID), string(describes.RefB.ElementRefID))
+}
+
+// TestBuildFromPackageSetsThenMerge exercises BuildFromPackageSets and
+// Document.Merge together, the way a caller assembling an SBOM from more
+// than one package-set chain (e.g. build + payload) would use them: the
+// package shared by both inputs should be deduplicated, and a checksum
+// mismatch on that shared package should surface as a real conflict error
+// rather than one only reproducible with hand-built fixtures.
+func TestBuildFromPackageSetsThenMerge(t *testing.T) {
+	a, err := BuildFromPackageSets(map[string][]rpmmd.PackageSpec{
+		"build": {
+			{Name: "bash", Version: "5.2", Release: "1.fc38", Arch: "x86_64", License: "GPLv3+", Checksum: "sha256:abc123"},
+		},
+	}, testMeta("image-a"))
+	require.NoError(t, err)
+
+	b, err := BuildFromPackageSets(map[string][]rpmmd.PackageSpec{
+		"payload": {
+			{Name: "bash", Version: "5.2", Release: "1.fc38", Arch: "x86_64", License: "GPLv3+", Checksum: "sha256:abc123"},
+			{Name: "coreutils", Version: "9.1", Release: "1.fc38", Arch: "x86_64", License: "GPLv3+", Checksum: "sha256:def456"},
+		},
+	}, testMeta("image-b"))
+	require.NoError(t, err)
+
+	require.NoError(t, a.Merge(b))
+
+	spdxDoc, err := a.ToSpdxDocument()
+	require.NoError(t, err)
+
+	// root(a) + root(b, ID collision so renamed) + bash (deduped) + coreutils
+	assert.Len(t, spdxDoc.Packages, 4)
+
+	var names []string
+	for _, pkg := range spdxDoc.Packages {
+		names = append(names, pkg.PackageName)
+	}
+	assert.Contains(t, names, "bash")
+	assert.Contains(t, names, "coreutils")
+}
+
+func TestBuildFromPackageSetsThenMergeConflict(t *testing.T) {
+	a, err := BuildFromPackageSets(map[string][]rpmmd.PackageSpec{
+		"build": {
+			{Name: "bash", Version: "5.2", Release: "1.fc38", Arch: "x86_64", License: "GPLv3+", Checksum: "sha256:abc123"},
+		},
+	}, testMeta("image-a"))
+	require.NoError(t, err)
+
+	b, err := BuildFromPackageSets(map[string][]rpmmd.PackageSpec{
+		"payload": {
+			{Name: "bash", Version: "5.2", Release: "1.fc38", Arch: "x86_64", License: "GPLv3+", Checksum: "sha256:different"},
+		},
+	}, testMeta("image-b"))
+	require.NoError(t, err)
+
+	err = a.Merge(b)
+	require.Error(t, err)
+	var conflictErr *PackageConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+}
+
+func TestBuildFromPackageSetsMultilibNotDeduplicated(t *testing.T) {
+	a, err := BuildFromPackageSets(map[string][]rpmmd.PackageSpec{
+		"build": {
+			{Name: "glibc", Version: "2.37", Release: "1.fc38", Arch: "x86_64", Checksum: "sha256:abc123"},
+		},
+	}, testMeta("image-a"))
+	require.NoError(t, err)
+
+	b, err := BuildFromPackageSets(map[string][]rpmmd.PackageSpec{
+		"build": {
+			{Name: "glibc", Version: "2.37", Release: "1.fc38", Arch: "i686", Checksum: "sha256:def456"},
+		},
+	}, testMeta("image-b"))
+	require.NoError(t, err)
+
+	require.NoError(t, a.Merge(b))
+
+	spdxDoc, err := a.ToSpdxDocument()
+	require.NoError(t, err)
+
+	// root(a) + root(b, renamed) + glibc.x86_64 + glibc.i686: different arches
+	// must not collide despite sharing name/version/release.
+	assert.Len(t, spdxDoc.Packages, 4)
+}
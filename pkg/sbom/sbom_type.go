@@ -0,0 +1,48 @@
+package sbom
+
+import (
+	"fmt"
+
+	"github.com/osbuild/images/pkg/rpmmd"
+)
+
+// SBOMType selects which standard a caller wants an SBOM emitted in. It is
+// an alias for [StandardType] rather than a separate type: both name the
+// same set of standards ([StandardTypeSpdx], [StandardTypeCycloneDX]), and
+// giving the user-facing selector its own name makes its purpose at a call
+// site ("which format do you want?") clearer than reusing StandardType,
+// which also does double duty as the internal tag on [Document].
+type SBOMType = StandardType
+
+// BuildDocumentAs builds an SBOM from package-set chains the same way
+// [BuildFromPackageSets] does, then converts the result to sbomType.
+// StandardTypeNone returns the SPDX document unconverted.
+//
+// NOTE: this is the conversion step an image-type's manifest generation
+// would call once wired up to let users pick an SBOMType for image builds,
+// but that wiring (a field on distro.ImageOptions, read by
+// distro.ImageType.Manifest) isn't done here: pkg/distro and pkg/manifest,
+// where that plumbing lives, aren't part of this tree. BuildDocumentAs is
+// the part that is self-contained in pkg/sbom; the caller-side selection
+// is left for whoever adds it alongside the rest of the manifest pipeline.
+func BuildDocumentAs(sbomType SBOMType, chains map[string][]rpmmd.PackageSpec, meta DocumentMetadata) (*Document, error) {
+	doc, err := BuildFromPackageSets(chains, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	switch sbomType {
+	case StandardTypeNone, StandardTypeSpdx:
+		return doc, nil
+	case StandardTypeCycloneDX:
+		bom, err := doc.ToCycloneDXDocument()
+		if err != nil {
+			return nil, err
+		}
+		return newDocument(bom)
+	default:
+		// sbomType.String() panics on an unrecognized value, so format the
+		// raw number instead of risking a panic over a bad argument.
+		return nil, fmt.Errorf("unsupported SBOM type: %d", uint64(sbomType))
+	}
+}
@@ -0,0 +1,28 @@
+package sbom
+
+import (
+	"testing"
+
+	"github.com/osbuild/images/pkg/rpmmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDocumentAs(t *testing.T) {
+	chains := map[string][]rpmmd.PackageSpec{
+		"build": {
+			{Name: "bash", Version: "5.2", Release: "1.fc38", Arch: "x86_64", License: "GPLv3+"},
+		},
+	}
+
+	spdxDoc, err := BuildDocumentAs(StandardTypeSpdx, chains, testMeta("image"))
+	require.NoError(t, err)
+	assert.Equal(t, StandardTypeSpdx, spdxDoc.docType)
+
+	cdxDoc, err := BuildDocumentAs(StandardTypeCycloneDX, chains, testMeta("image"))
+	require.NoError(t, err)
+	assert.Equal(t, StandardTypeCycloneDX, cdxDoc.docType)
+
+	_, err = BuildDocumentAs(StandardType(99), chains, testMeta("image"))
+	assert.Error(t, err)
+}
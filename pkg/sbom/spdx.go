@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/CycloneDX/cyclonedx-go"
 	spdx_json "github.com/spdx/tools-golang/json"
 	"github.com/spdx/tools-golang/spdx"
 )
@@ -37,6 +38,8 @@ func (d *Document) ToSpdxDocument() (*spdx.Document, error) {
 	switch d.docType {
 	case StandardTypeSpdx:
 		return d.document.(*spdx.Document), nil
+	case StandardTypeCycloneDX:
+		return cycloneDXToSpdx(d.document.(*cyclonedx.BOM))
 	default:
 		return nil, fmt.Errorf("conversion to SPDX document not supported for document type: %s", d.docType)
 	}